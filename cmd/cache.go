@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/eguzki/konfluxctl/cmd/cache"
+	"github.com/spf13/cobra"
+)
+
+func cacheCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the on-disk image lineage cache",
+		Long:  "Manage the on-disk image lineage cache",
+	}
+
+	cmd.AddCommand(cache.ListCommand())
+	cmd.AddCommand(cache.InspectCommand())
+	cmd.AddCommand(cache.PurgeCommand())
+	return cmd
+}
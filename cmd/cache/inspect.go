@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/eguzki/konfluxctl/internal/output"
+)
+
+//konfluxctl cache inspect KEY
+
+var inspectOutput = output.NewFlags(output.FormatText)
+
+func InspectCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "inspect KEY",
+		Short: "Show a cached image lineage record",
+		Long:  "Show a cached image lineage record. KEY can be any unique prefix of the record key",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runInspect,
+	}
+
+	inspectOutput.AddFlags(cmd)
+
+	return cmd
+}
+
+func runInspect(cmd *cobra.Command, args []string) error {
+	printer, err := inspectOutput.Printer()
+	if err != nil {
+		return err
+	}
+
+	store, err := defaultStore()
+	if err != nil {
+		return err
+	}
+
+	record, err := store.Find(args[0])
+	if err != nil {
+		return err
+	}
+
+	return printer.Print(cmd.OutOrStdout(), record)
+}
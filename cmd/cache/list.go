@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/eguzki/konfluxctl/internal/output"
+)
+
+//konfluxctl cache list
+
+var listOutput = output.NewFlags(output.FormatTable, output.FormatWide, output.FormatName)
+
+func ListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the cached image lineage records",
+		Long:  "List the cached image lineage records, expired ones included",
+		Args:  cobra.NoArgs,
+		RunE:  runList,
+	}
+
+	listOutput.AddFlags(cmd)
+
+	return cmd
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	printer, err := listOutput.Printer()
+	if err != nil {
+		return err
+	}
+
+	store, err := defaultStore()
+	if err != nil {
+		return err
+	}
+
+	records, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	return printer.Print(cmd.OutOrStdout(), records)
+}
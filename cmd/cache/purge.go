@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+//konfluxctl cache purge [KEY...]
+
+var purgeExpired bool
+
+func PurgeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "purge [KEY...]",
+		Short: "Delete cached image lineage records",
+		Long:  "Delete the given cached image lineage records. Without KEY, every record is deleted",
+		RunE:  runPurge,
+	}
+
+	cmd.Flags().BoolVar(&purgeExpired, "expired", false, "Delete only the expired records, and the unreadable ones")
+
+	return cmd
+}
+
+func runPurge(cmd *cobra.Command, args []string) error {
+	if purgeExpired && len(args) > 0 {
+		return fmt.Errorf("--expired cannot be used with KEY arguments")
+	}
+
+	store, err := defaultStore()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		deleted, err := store.Purge(purgeExpired)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%d records deleted\n", deleted)
+		return nil
+	}
+
+	for _, key := range args {
+		record, err := store.Find(key)
+		if err != nil {
+			return err
+		}
+		if err := store.Delete(record.Key); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s deleted\n", record.Key)
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package cache
+
+import (
+	"github.com/eguzki/konfluxctl/internal/cache"
+)
+
+func defaultStore() (*cache.Store, error) {
+	cacheDir, err := cache.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return cache.NewStore(cacheDir), nil
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/eguzki/konfluxctl/internal/config"
+	"github.com/eguzki/konfluxctl/internal/export"
+	"github.com/eguzki/konfluxctl/internal/kube"
+	"github.com/eguzki/konfluxctl/internal/metadata"
+)
+
+//konfluxctl export --application APP --output DIR
+//konfluxctl export --rpa RPA --output FILE.tar.gz
+
+var (
+	exportApplication string
+	exportRPA         string
+	exportNamespace   string
+	exportOutput      string
+)
+
+func exportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the release graph of an application or a ReleasePlanAdmission",
+		Long: `Export the release graph of an application or a ReleasePlanAdmission, for offline analysis with --from-dir, or --from-file for tarballs:
+ReleasePlanAdmissions, ReleasePlans, Releases, Snapshots, Applications and Components, one manifest per object,
+with an index file describing what was captured and when. Managed fields are stripped and Secrets are never exported.
+The output is a directory, or a gzipped tarball when it ends with .tar.gz or .tgz`,
+		Args: cobra.NoArgs,
+		RunE: runExport,
+	}
+
+	cmd.Flags().StringVar(&exportApplication, "application", "", "Export the release graph of the application")
+	cmd.Flags().StringVar(&exportRPA, "rpa", "", "Export the release graph of the ReleasePlanAdmission")
+	cmd.MarkFlagsMutuallyExclusive("application", "rpa")
+	cmd.MarkFlagsOneRequired("application", "rpa")
+	cmd.Flags().StringVarP(&exportNamespace, "namespace", "n", "", fmt.Sprintf("Namespace of the application (default the namespace of the current kubeconfig context) or of the ReleasePlanAdmission (default the first one from config file or %q)", metadata.DefaultReleasePlanAdmissionNamespace))
+	cmd.Flags().StringVar(&exportOutput, "output", "", "Output directory, empty or not existing, or tarball when it ends with .tar.gz or .tgz (required)")
+
+	if err := cmd.MarkFlagRequired("output"); err != nil {
+		fmt.Println("Error setting 'output' flag as required:", err)
+		os.Exit(1)
+	}
+
+	return cmd
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	k8sClient, err := kube.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	cluster := "offline"
+	if !kube.IsOffline(ctx) {
+		if cluster, err = kube.CurrentCluster(); err != nil {
+			return err
+		}
+	}
+
+	var (
+		objs  []client.Object
+		scope string
+	)
+
+	if exportApplication != "" {
+		namespace, nsErr := kube.Namespace(exportNamespace)
+		if nsErr != nil {
+			return nsErr
+		}
+		scope = fmt.Sprintf("Application %s/%s", namespace, exportApplication)
+		objs, err = metadata.ApplicationGraph(ctx, k8sClient, namespace, exportApplication)
+	} else {
+		namespace := exportNamespace
+		if namespace == "" {
+			namespaces := config.FromContext(ctx).ReleasePlanAdmissionNamespaces(nil, false, metadata.DefaultReleasePlanAdmissionNamespace)
+			if len(namespaces) == 0 {
+				return errors.New("--namespace is required when ReleasePlanAdmissions are looked up across all namespaces")
+			}
+			namespace = namespaces[0]
+		}
+		scope = fmt.Sprintf("ReleasePlanAdmission %s/%s", namespace, exportRPA)
+		objs, err = metadata.ReleasePlanAdmissionGraph(ctx, k8sClient, namespace, exportRPA)
+	}
+	if err != nil {
+		return err
+	}
+
+	scheme, err := kube.NewScheme()
+	if err != nil {
+		return err
+	}
+
+	index, err := export.Write(exportOutput, scheme, objs, export.Index{
+		CapturedAt: metav1.NewTime(time.Now().UTC().Truncate(time.Second)),
+		Cluster:    cluster,
+		Scope:      scope,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%d objects of %s exported to %s\n", len(index.Objects), scope, exportOutput)
+	return nil
+}
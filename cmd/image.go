@@ -13,5 +13,6 @@ func imageCommand() *cobra.Command {
 	}
 
 	cmd.AddCommand(image.MetadataCommand())
+	cmd.AddCommand(image.DiffCommand())
 	return cmd
 }
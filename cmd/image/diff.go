@@ -0,0 +1,123 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eguzki/konfluxctl/internal/cache"
+	"github.com/eguzki/konfluxctl/internal/config"
+	"github.com/eguzki/konfluxctl/internal/kube"
+	"github.com/eguzki/konfluxctl/internal/metadata"
+	"github.com/eguzki/konfluxctl/internal/output"
+	"github.com/eguzki/konfluxctl/internal/registry"
+)
+
+//konfluxctl image diff --from IMAGE_URL --to IMAGE_URL
+
+var (
+	diffFrom             string
+	diffTo               string
+	diffOutput           = output.NewFlags(output.FormatText)
+	diffRPANamespaces    []string
+	diffRPAAllNamespaces bool
+	diffConcurrency      int
+	diffNoCache          bool
+	// diffMatchPlatforms matches image indexes with their platform manifests
+	diffMatchPlatforms bool
+)
+
+func DiffCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare the konflux metadata of two Docker/OCI images",
+		Long: `Compare the primary lineage paths of two Docker/OCI images: source revision, snapshot, release,
+advisory, tags... When both images are built from the same GitHub or GitLab repository,
+the URL comparing both source revisions is shown`,
+		RunE: runDiff,
+	}
+
+	cmd.Flags().StringVar(&diffFrom, "from", "", "Docker/OCI image URL to compare from, by digest or by tag (required)")
+	cmd.Flags().StringVar(&diffTo, "to", "", "Docker/OCI image URL to compare to, by digest or by tag (required)")
+	diffOutput.AddFlags(cmd)
+	cmd.Flags().StringArrayVar(&diffRPANamespaces, "rpa-namespace", nil, fmt.Sprintf("Namespace where ReleasePlanAdmissions are looked up. Repeatable (default from config file or %q)", metadata.DefaultReleasePlanAdmissionNamespace))
+	cmd.Flags().BoolVar(&diffRPAAllNamespaces, "all-rpa-namespaces", false, "Look up ReleasePlanAdmissions across all namespaces the user can read")
+	cmd.MarkFlagsMutuallyExclusive("rpa-namespace", "all-rpa-namespaces")
+	cmd.Flags().IntVar(&diffConcurrency, "concurrency", 4, "Maximum number of lineage graph nodes expanded concurrently")
+	cmd.Flags().BoolVar(&diffNoCache, "no-cache", false, "Do not read nor write the on-disk lineage cache")
+	cmd.Flags().BoolVar(&diffMatchPlatforms, "match-platforms", false, "Match multi-arch image indexes with their platform manifests. Fetches from the registry the image index of the components mapped to the image repository")
+
+	for _, flag := range []string{"from", "to"} {
+		if err := cmd.MarkFlagRequired(flag); err != nil {
+			fmt.Printf("Error setting '%s' flag as required: %v\n", flag, err)
+			os.Exit(1)
+		}
+	}
+
+	return cmd
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	printer, err := diffOutput.Printer()
+	if err != nil {
+		return err
+	}
+
+	if diffConcurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1, got %d", diffConcurrency)
+	}
+
+	// replays are hermetic, only images pinned by digest resolve
+	resolver := registry.NewResolver(registry.WithNetwork(!kube.IsReplaying(ctx)))
+	if diffMatchPlatforms {
+		// offline and replayed runs read nothing but their inputs
+		if kube.IsOffline(ctx) {
+			return errors.New("--match-platforms fetches image indexes from the registry, it is not supported offline")
+		}
+		ctx = metadata.WithManifestIndex(ctx, resolver)
+	}
+
+	fromPath, err := primaryPath(ctx, resolver, diffFrom)
+	if err != nil {
+		return err
+	}
+
+	toPath, err := primaryPath(ctx, resolver, diffTo)
+	if err != nil {
+		return err
+	}
+
+	return printer.Print(cmd.OutOrStdout(), metadata.DiffPaths(diffFrom, *fromPath, diffTo, *toPath))
+}
+
+// primaryPath returns the most recently released complete path of the image
+func primaryPath(ctx context.Context, resolver *registry.Resolver, image string) (*metadata.Path, error) {
+	imageRef, err := resolveImage(ctx, resolver, image)
+	if err != nil {
+		return nil, err
+	}
+
+	searchOptions := []metadata.SearchOption{metadata.WithConcurrency(diffConcurrency)}
+	namespaces := config.FromContext(ctx).ReleasePlanAdmissionNamespaces(diffRPANamespaces, diffRPAAllNamespaces, metadata.DefaultReleasePlanAdmissionNamespace)
+	// Only complete paths of the default search are cached
+	cacheSettings := lineageCache{enabled: !diffNoCache && !diffMatchPlatforms, ttl: cache.DefaultTTL}
+	paths, err := cachedPaths(ctx, imageRef, namespaces, cacheSettings, func() ([]metadata.Path, error) {
+		return searchPaths(ctx, imageRef, namespaces, searchOptions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no metadata found for image %s", image)
+	}
+
+	// paths are sorted, the first one is the primary
+	return &paths[0], nil
+}
@@ -2,26 +2,49 @@ package image
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
+	"time"
 
-	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
-	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
-	k8sruntime "k8s.io/apimachinery/pkg/runtime"
-	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/client/config"
 
+	"github.com/eguzki/konfluxctl/internal/cache"
+	"github.com/eguzki/konfluxctl/internal/config"
+	"github.com/eguzki/konfluxctl/internal/kube"
 	"github.com/eguzki/konfluxctl/internal/metadata"
+	"github.com/eguzki/konfluxctl/internal/output"
+	"github.com/eguzki/konfluxctl/internal/registry"
 	"github.com/eguzki/konfluxctl/internal/utils"
 )
 
 //konfluxctl image metadata --image IMAGE_URL
 
 var (
-	imageURL            string
-	imageMetadataFormat string
+	imageURL             string
+	imageMetadataOutput  = output.NewFlags(output.FormatText, output.FormatTable, output.FormatWide, formatDOT, formatMermaid)
+	imageMetadataAll     bool
+	rpaNamespaces        []string
+	rpaAllNamespaces     bool
+	imageMetadataExplain bool
+	imageMetadataPartial bool
+	requiredFields       []string
+	searchConcurrency    int
+	imageMetadataNoCache bool
+	imageMetadataRefresh bool
+	imageMetadataTTL     time.Duration
+	// imageMetadataMatchPlatforms matches image indexes with their platform manifests
+	imageMetadataMatchPlatforms bool
+)
+
+const (
+	// formatDOT renders the explored lineage graph in Graphviz DOT
+	formatDOT = "dot"
+	// formatMermaid renders the explored lineage graph as a Mermaid flowchart
+	formatMermaid = "mermaid"
 )
 
 func MetadataCommand() *cobra.Command {
@@ -32,8 +55,23 @@ func MetadataCommand() *cobra.Command {
 		RunE:  runMetadata,
 	}
 
-	cmd.Flags().StringVar(&imageURL, "image", "", "Docker/OCI image URL (required)")
-	cmd.Flags().StringVarP(&imageMetadataFormat, "output-format", "o", "", "Output format: 'yaml' or 'json'.")
+	cmd.Flags().StringVar(&imageURL, "image", "", "Docker/OCI image URL, by digest or by tag (required)")
+	imageMetadataOutput.AddFlags(cmd)
+	cmd.Flags().BoolVar(&imageMetadataAll, "all", false, "Return every complete path, most recently released first. By default, only the primary (most recent) path is returned")
+
+	cmd.Flags().StringArrayVar(&rpaNamespaces, "rpa-namespace", nil, fmt.Sprintf("Namespace where ReleasePlanAdmissions are looked up. Repeatable (default from config file or %q)", metadata.DefaultReleasePlanAdmissionNamespace))
+	cmd.Flags().BoolVar(&rpaAllNamespaces, "all-rpa-namespaces", false, "Look up ReleasePlanAdmissions across all namespaces the user can read")
+	cmd.MarkFlagsMutuallyExclusive("rpa-namespace", "all-rpa-namespaces")
+	cmd.Flags().BoolVar(&imageMetadataExplain, "explain", false, "Report how the lineage search went and where it broke, as a diagnosis tree")
+	cmd.Flags().BoolVar(&imageMetadataPartial, "partial", false, "When no complete path is found, return the deepest incomplete paths with the missing fields")
+	cmd.Flags().IntVar(&searchConcurrency, "concurrency", 4, "Maximum number of lineage graph nodes expanded concurrently")
+	cmd.Flags().StringSliceVar(&requiredFields, "require", lo.Map(metadata.DefaultRequiredFields, func(f metadata.Field, _ int) string { return string(f) }),
+		fmt.Sprintf("Fields required for a path to be complete. Valid fields: %s", strings.Join(lo.Map(metadata.AllFields, func(f metadata.Field, _ int) string { return string(f) }), ",")))
+	cmd.Flags().BoolVar(&imageMetadataNoCache, "no-cache", false, "Do not read nor write the on-disk lineage cache")
+	cmd.Flags().BoolVar(&imageMetadataRefresh, "refresh", false, "Ignore cached lineage, resolve it again and update the cache")
+	cmd.Flags().DurationVar(&imageMetadataTTL, "cache-ttl", cache.DefaultTTL, "Lifetime of the cached lineage")
+	cmd.MarkFlagsMutuallyExclusive("no-cache", "refresh")
+	cmd.Flags().BoolVar(&imageMetadataMatchPlatforms, "match-platforms", false, "Match multi-arch image indexes with their platform manifests. Fetches from the registry the image index of the components mapped to the image repository")
 
 	if err := cmd.MarkFlagRequired("image"); err != nil {
 		fmt.Println("Error setting 'image' flag as required:", err)
@@ -44,69 +82,196 @@ func MetadataCommand() *cobra.Command {
 }
 
 func runMetadata(cmd *cobra.Command, args []string) error {
-	scheme := k8sruntime.NewScheme()
-	if err := konfluxapi.AddToScheme(scheme); err != nil {
-		return err
-	}
-	if err := applicationapi.AddToScheme(scheme); err != nil {
-		return err
-	}
-
 	ctx, cancel := context.WithCancel(cmd.Context())
 	defer cancel()
 
-	configuration, err := config.GetConfig()
+	printer, err := imageMetadataOutput.Printer()
 	if err != nil {
 		return err
 	}
 
-	k8sClient, err := client.New(configuration, client.Options{Scheme: scheme})
+	format := imageMetadataOutput.Format()
+	// dot and mermaid render every explored node, they are read from the search trace
+	graphOutput := format == formatDOT || format == formatMermaid
+	// the diagnosis tree is not tabular
+	if imageMetadataExplain && (format == output.FormatTable || format == output.FormatWide) {
+		return fmt.Errorf("--explain does not support output format %q", format)
+	}
+
+	required, err := metadata.ParseFields(requiredFields)
 	if err != nil {
 		return err
 	}
 
-	// 1. Parse the reference string
-	imageRef, err := utils.ParseImageURL(imageURL)
+	if searchConcurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1, got %d", searchConcurrency)
+	}
+
+	searchOptions := []metadata.SearchOption{
+		metadata.WithRequiredFields(required),
+		metadata.WithConcurrency(searchConcurrency),
+	}
+	if imageMetadataPartial {
+		searchOptions = append(searchOptions, metadata.WithPartialPaths())
+	}
+
+	// replays are hermetic, only images pinned by digest resolve
+	resolver := registry.NewResolver(registry.WithNetwork(!kube.IsReplaying(ctx)))
+	// snapshots record the digest of multi-arch image indexes, users usually have a platform manifest digest
+	if imageMetadataMatchPlatforms {
+		// offline and replayed runs read nothing but their inputs
+		if kube.IsOffline(ctx) {
+			return errors.New("--match-platforms fetches image indexes from the registry, it is not supported offline")
+		}
+		ctx = metadata.WithManifestIndex(ctx, resolver)
+	}
+
+	imageRef, err := resolveImage(ctx, resolver, imageURL)
 	if err != nil {
 		return err
 	}
 
 	slog.Debug("metadata", "image ref", imageRef)
 
-	rpaList, err := metadata.ReleasePlanAdmissionList(ctx, k8sClient, imageRef.FamiliarName())
-	if err != nil {
-		return err
+	var trace *metadata.Trace
+	if imageMetadataExplain || graphOutput {
+		trace = metadata.NewTrace(fmt.Sprintf("Image: %s", imageURL))
+		ctx = metadata.WithTrace(ctx, trace)
 	}
 
-	slog.Debug("metadata", "releaseplanadmission (rpa) candidates", len(rpaList))
+	// Only complete paths of the default search are cached
+	cacheSettings := lineageCache{
+		enabled: !imageMetadataNoCache && trace == nil && !imageMetadataPartial && !imageMetadataMatchPlatforms && !cmd.Flags().Changed("require"),
+		refresh: imageMetadataRefresh,
+		ttl:     imageMetadataTTL,
+	}
+
+	namespaces := config.FromContext(ctx).ReleasePlanAdmissionNamespaces(rpaNamespaces, rpaAllNamespaces, metadata.DefaultReleasePlanAdmissionNamespace)
 
-	paths, err := metadata.DepthFirstSearch(ctx, k8sClient, imageRef, rpaList)
+	paths, err := cachedPaths(ctx, imageRef, namespaces, cacheSettings, func() ([]metadata.Path, error) {
+		return searchPaths(ctx, imageRef, namespaces, searchOptions)
+	})
+	if errors.Is(err, metadata.ErrNoImageTags) {
+		return fmt.Errorf("%w. Use --partial, or leave %s out of --require", err, metadata.FieldImageTags)
+	}
 	if err != nil {
 		return err
 	}
 
+	switch format {
+	case formatDOT:
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), trace.ToDOT())
+		return nil
+	case formatMermaid:
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), trace.ToMermaid())
+		return nil
+	}
+
+	if trace != nil {
+		return printer.Print(cmd.OutOrStdout(), trace)
+	}
+
 	if len(paths) == 0 {
-		fmt.Println("🧐 No metadata found")
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "🧐 No metadata found")
 		return nil
+	}
 
+	slog.Debug("metadata", "complete paths", len(paths))
+
+	if imageMetadataAll {
+		return printer.Print(cmd.OutOrStdout(), metadata.PathList(paths))
 	}
 
-	switch imageMetadataFormat {
-	case "json":
-		jsonStr, err := paths[0].ToJSON()
+	// paths are sorted, the first one is the primary
+	return printer.Print(cmd.OutOrStdout(), paths[0])
+}
+
+// resolveImage pins tags to digests, then parses the reference string
+func resolveImage(ctx context.Context, resolver *registry.Resolver, image string) (*utils.ImageURL, error) {
+	pinnedImageURL, err := resolver.Resolve(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.ParseImageURL(pinnedImageURL)
+}
+
+// searchPaths looks up the lineage of the image from the ReleasePlanAdmissions of the namespaces, all readable namespaces when empty
+func searchPaths(ctx context.Context, imageRef *utils.ImageURL, namespaces []string, searchOptions []metadata.SearchOption) ([]metadata.Path, error) {
+	rawClient, err := kube.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// lineage paths share many objects, fetch them once
+	k8sClient := kube.NewCachingClient(rawClient)
+	defer k8sClient.LogStats()
+
+	slog.Debug("metadata", "releaseplanadmission namespaces", namespaces)
+
+	rpaList, err := metadata.ReleasePlanAdmissionList(ctx, k8sClient, imageRef.FamiliarName(), namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("metadata", "releaseplanadmission (rpa) candidates", len(rpaList))
+
+	return metadata.DepthFirstSearch(ctx, k8sClient, imageRef, rpaList, searchOptions...)
+}
+
+// lineageCache are the on-disk lineage cache settings of a command
+type lineageCache struct {
+	enabled bool
+	// refresh ignores the cached lineage, it is resolved again and the cache updated
+	refresh bool
+	ttl     time.Duration
+}
+
+// cachedPaths returns the paths from the on-disk cache, calling search on cache miss.
+// Released images are immutable, so once found, the lineage of a digest does not change.
+// Records are keyed by the ReleasePlanAdmission namespaces too: other namespaces may give another lineage.
+// Cache failures are not fatal. Offline, the cache is not used: it is keyed by cluster.
+// Nor when recording: cache hits would leave the recording empty.
+func cachedPaths(ctx context.Context, imageRef *utils.ImageURL, namespaces []string, settings lineageCache, search func() ([]metadata.Path, error)) ([]metadata.Path, error) {
+	if !settings.enabled || kube.IsOffline(ctx) || kube.IsRecording(ctx) {
+		return search()
+	}
+
+	cluster, err := kube.CurrentCluster()
+	if err != nil {
+		return nil, err
+	}
+
+	cacheDir, err := cache.DefaultDir()
+	if err != nil {
+		slog.Debug("lineage cache disabled", "error", err)
+		return search()
+	}
+
+	store := cache.NewStore(cacheDir)
+	image := fmt.Sprintf("%s@%s", imageRef.FamiliarName(), imageRef.Digest())
+
+	if !settings.refresh {
+		record, ok, err := store.Get(cluster, image, namespaces)
 		if err != nil {
-			return err
+			slog.Debug("reading lineage cache", "error", err)
 		}
-		_, _ = fmt.Fprintln(cmd.OutOrStdout(), jsonStr)
-	case "yaml":
-		yamlStr, err := paths[0].ToYAML()
-		if err != nil {
-			return err
+		if ok {
+			slog.Debug("lineage cache hit", "key", record.Key, "created", record.CreatedAt)
+			return record.Paths, nil
+		}
+	}
+
+	paths, err := search()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(paths) > 0 {
+		if err := store.Put(cluster, image, namespaces, paths, settings.ttl); err != nil {
+			slog.Debug("writing lineage cache", "error", err)
 		}
-		_, _ = fmt.Fprintln(cmd.OutOrStdout(), yamlStr)
-	default:
-		_, _ = fmt.Fprintln(cmd.OutOrStdout(), paths[0])
 	}
 
-	return nil
+	return paths, nil
 }
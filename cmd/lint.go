@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/eguzki/konfluxctl/cmd/lint"
+	"github.com/spf13/cobra"
+)
+
+func lintCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Konflux release configuration checks",
+		Long:  "Konflux release configuration checks",
+	}
+
+	cmd.AddCommand(lint.ApplicationCommand())
+	return cmd
+}
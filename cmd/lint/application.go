@@ -0,0 +1,67 @@
+package lint
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eguzki/konfluxctl/internal/kube"
+	"github.com/eguzki/konfluxctl/internal/metadata"
+	"github.com/eguzki/konfluxctl/internal/output"
+)
+
+//konfluxctl lint application NAME
+
+var (
+	applicationNamespace string
+	applicationOutput    = output.NewFlags(output.FormatText)
+)
+
+func ApplicationCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "application NAME",
+		Short: "Lint the release configuration of a Konflux Application",
+		Long: `Lint the release configuration of a Konflux Application: every ReleasePlan is Matched,
+the matched ReleasePlanAdmissions map every component, mapped repositories are valid and have tags.
+Exits with an error when some finding is an error`,
+		Args: cobra.ExactArgs(1),
+		RunE: runApplication,
+	}
+
+	cmd.Flags().StringVarP(&applicationNamespace, "namespace", "n", "", "Tenant namespace of the Application (default the namespace of the current kubeconfig context)")
+	applicationOutput.AddFlags(cmd)
+
+	return cmd
+}
+
+func runApplication(cmd *cobra.Command, args []string) error {
+	printer, err := applicationOutput.Printer()
+	if err != nil {
+		return err
+	}
+
+	namespace, err := kube.Namespace(applicationNamespace)
+	if err != nil {
+		return err
+	}
+
+	k8sClient, err := kube.NewClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	report, err := metadata.LintApplication(cmd.Context(), k8sClient, namespace, args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := printer.Print(cmd.OutOrStdout(), report); err != nil {
+		return err
+	}
+
+	if report.HasErrors() {
+		return errors.New("release configuration has errors")
+	}
+
+	return nil
+}
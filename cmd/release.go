@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/eguzki/konfluxctl/cmd/release"
+	"github.com/spf13/cobra"
+)
+
+func releaseCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "release",
+		Short: "Konflux Release related utility",
+		Long:  "Konflux Release related utility",
+	}
+
+	cmd.AddCommand(release.ListCommand())
+	cmd.AddCommand(release.GetCommand())
+	cmd.AddCommand(release.CreateCommand())
+	cmd.AddCommand(release.WaitCommand())
+	return cmd
+}
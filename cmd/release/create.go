@@ -0,0 +1,105 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eguzki/konfluxctl/internal/kube"
+	"github.com/eguzki/konfluxctl/internal/metadata"
+	"github.com/eguzki/konfluxctl/internal/output"
+)
+
+//konfluxctl release create --snapshot SNAPSHOT --release-plan PLAN [--data FILE] [--wait]
+
+var (
+	createNamespace   string
+	createSnapshot    string
+	createReleasePlan string
+	createDataFile    string
+	createDryRun      bool
+	createWait        bool
+	createTimeout     time.Duration
+	createOutput      = output.NewFlags(output.FormatYAML, output.FormatName)
+)
+
+func CreateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a Konflux Release of a snapshot",
+		Long:  "Create a Konflux Release of a snapshot with a release plan. The snapshot and the release plan are validated first",
+		Args:  cobra.NoArgs,
+		RunE:  runCreate,
+	}
+
+	cmd.Flags().StringVarP(&createNamespace, "namespace", "n", "", "Tenant namespace of the Release (default the namespace of the current kubeconfig context)")
+	cmd.Flags().StringVar(&createSnapshot, "snapshot", "", "Snapshot to release (required)")
+	cmd.Flags().StringVar(&createReleasePlan, "release-plan", "", "ReleasePlan to release the snapshot with (required)")
+	cmd.Flags().StringVar(&createDataFile, "data", "", "YAML or JSON file with the data passed to the managed release pipeline")
+	cmd.Flags().BoolVar(&createDryRun, "dry-run", false, "Print the Release instead of creating it")
+	cmd.Flags().BoolVar(&createWait, "wait", false, "Wait for the Release to finish, like 'release wait'")
+	cmd.Flags().DurationVar(&createTimeout, "timeout", time.Hour, "Maximum time to wait with --wait, 0 waits forever")
+	cmd.MarkFlagsMutuallyExclusive("dry-run", "wait")
+	createOutput.AddFlags(cmd)
+
+	for _, flag := range []string{"snapshot", "release-plan"} {
+		if err := cmd.MarkFlagRequired(flag); err != nil {
+			fmt.Printf("Error setting '%s' flag as required: %s\n", flag, err)
+			os.Exit(1)
+		}
+	}
+
+	return cmd
+}
+
+func runCreate(cmd *cobra.Command, args []string) error {
+	printer, err := createOutput.Printer()
+	if err != nil {
+		return err
+	}
+	if !createDryRun && cmd.Flags().Changed("output-format") {
+		return errors.New("--output-format formats the Release printed by --dry-run, it requires --dry-run")
+	}
+
+	namespace, err := kube.Namespace(createNamespace)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if createDataFile != "" {
+		data, err = os.ReadFile(createDataFile)
+		if err != nil {
+			return fmt.Errorf("reading release data: %w", err)
+		}
+	}
+
+	k8sClient, err := kube.NewWatchClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	release, err := metadata.NewRelease(cmd.Context(), k8sClient, namespace, createSnapshot, createReleasePlan, data)
+	if err != nil {
+		return err
+	}
+
+	if createDryRun {
+		return printer.Print(cmd.OutOrStdout(), metadata.ReleaseManifest{Release: release})
+	}
+
+	if err := k8sClient.Create(cmd.Context(), release); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Release %s/%s created\n", release.Namespace, release.Name)
+
+	if !createWait {
+		return nil
+	}
+
+	return waitForRelease(cmd, k8sClient, release.Namespace, release.Name, createTimeout)
+}
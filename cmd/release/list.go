@@ -0,0 +1,113 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/eguzki/konfluxctl/internal/kube"
+	"github.com/eguzki/konfluxctl/internal/metadata"
+	"github.com/eguzki/konfluxctl/internal/output"
+)
+
+//konfluxctl release list [--application APP] [--release-plan PLAN] [--status STATUS] [--since TIME] [--until TIME]
+
+var (
+	listNamespaces  []string
+	listApplication string
+	listReleasePlan string
+	listStatus      string
+	listSince       string
+	listUntil       string
+	listOutput      = output.NewFlags(output.FormatTable, output.FormatWide, output.FormatName)
+)
+
+func ListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List Konflux Releases",
+		Long:  "List Konflux Releases, most recently created first",
+		Args:  cobra.NoArgs,
+		RunE:  runList,
+	}
+
+	cmd.Flags().StringArrayVarP(&listNamespaces, "namespace", "n", nil, "Tenant namespace where Releases are looked up. Repeatable (default all namespaces the user can read)")
+	cmd.Flags().StringVar(&listApplication, "application", "", "Only releases of the application")
+	cmd.Flags().StringVar(&listReleasePlan, "release-plan", "", "Only releases created from the release plan")
+	cmd.Flags().StringVar(&listStatus, "status", "", fmt.Sprintf("Only releases in the status. Valid statuses: %s",
+		strings.Join(lo.Map(metadata.AllReleaseStatuses, func(s metadata.ReleaseStatus, _ int) string { return string(s) }), ",")))
+	cmd.Flags().StringVar(&listSince, "since", "", "Only releases created after the time: RFC3339 timestamp, date (2006-01-02) or duration ago (24h)")
+	cmd.Flags().StringVar(&listUntil, "until", "", "Only releases created before the time: RFC3339 timestamp, date (2006-01-02) or duration ago (24h)")
+	listOutput.AddFlags(cmd)
+
+	return cmd
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	printer, err := listOutput.Printer()
+	if err != nil {
+		return err
+	}
+
+	filter := metadata.ReleaseFilter{
+		Application: listApplication,
+		ReleasePlan: listReleasePlan,
+	}
+
+	if listStatus != "" {
+		status, err := metadata.ParseReleaseStatus(listStatus)
+		if err != nil {
+			return err
+		}
+		filter.Status = status
+	}
+
+	now := time.Now()
+	if filter.Since, err = parseTime(listSince, now); err != nil {
+		return fmt.Errorf("--since: %w", err)
+	}
+	if filter.Until, err = parseTime(listUntil, now); err != nil {
+		return fmt.Errorf("--until: %w", err)
+	}
+
+	rawClient, err := kube.NewClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	// releases share their release plans, fetch them once
+	k8sClient := kube.NewCachingClient(rawClient)
+	defer k8sClient.LogStats()
+
+	releases, err := metadata.ListReleases(cmd.Context(), k8sClient, filter, listNamespaces)
+	if err != nil {
+		return err
+	}
+
+	return printer.Print(cmd.OutOrStdout(), releases)
+}
+
+// parseTime accepts RFC3339 timestamps, dates and durations, relative to now.
+// The zero time is returned for empty values.
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q: expected RFC3339 timestamp, date (2006-01-02) or duration (24h)", value)
+}
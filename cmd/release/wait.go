@@ -0,0 +1,84 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/eguzki/konfluxctl/internal/kube"
+	"github.com/eguzki/konfluxctl/internal/metadata"
+)
+
+//konfluxctl release wait NAME --timeout 2h
+
+var (
+	waitNamespace string
+	waitTimeout   time.Duration
+)
+
+func WaitCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wait NAME",
+		Short: "Wait for a Konflux Release to finish",
+		Long: `Wait for a Konflux Release to finish, streaming the condition transitions to stderr.
+Exits 0 when the Release is released, non-zero with the failure reason otherwise`,
+		Args: cobra.ExactArgs(1),
+		RunE: runWait,
+	}
+
+	cmd.Flags().StringVarP(&waitNamespace, "namespace", "n", "", "Tenant namespace of the Release (default the namespace of the current kubeconfig context)")
+	cmd.Flags().DurationVar(&waitTimeout, "timeout", time.Hour, "Maximum time to wait, 0 waits forever")
+
+	return cmd
+}
+
+func runWait(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	namespace, err := kube.Namespace(waitNamespace)
+	if err != nil {
+		return err
+	}
+
+	k8sClient, err := kube.NewWatchClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	return waitForRelease(cmd, k8sClient, namespace, name, waitTimeout)
+}
+
+// waitForRelease waits for the release to finish, streaming the condition transitions to stderr
+func waitForRelease(cmd *cobra.Command, k8sClient client.WithWatch, namespace, name string, timeout time.Duration) error {
+	ctx := cmd.Context()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	err := metadata.WaitForRelease(ctx, k8sClient, namespace, name, func(condition metav1.Condition) {
+		line := fmt.Sprintf("%s %s=%s", time.Now().UTC().Format(time.RFC3339), condition.Type, condition.Status)
+		if condition.Reason != "" {
+			line += fmt.Sprintf(" (%s)", condition.Reason)
+		}
+		if condition.Message != "" {
+			line += ": " + condition.Message
+		}
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), line)
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s waiting for release %s/%s", timeout, namespace, name)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✅ Release %s/%s released\n", namespace, name)
+	return nil
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/eguzki/konfluxctl/cmd/releaseplan"
+	"github.com/spf13/cobra"
+)
+
+func releasePlanCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "releaseplan",
+		Short: "Konflux ReleasePlan related utility",
+		Long:  "Konflux ReleasePlan related utility",
+	}
+
+	cmd.AddCommand(releaseplan.ListCommand())
+	cmd.AddCommand(releaseplan.GetCommand())
+	return cmd
+}
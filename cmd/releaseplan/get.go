@@ -0,0 +1,68 @@
+package releaseplan
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eguzki/konfluxctl/internal/kube"
+	"github.com/eguzki/konfluxctl/internal/metadata"
+	"github.com/eguzki/konfluxctl/internal/output"
+)
+
+//konfluxctl releaseplan get NAME [--releases N]
+
+var (
+	getNamespace string
+	getReleases  int
+	getOutput    = output.NewFlags(output.FormatText, output.FormatTable, output.FormatWide, output.FormatName)
+)
+
+func GetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get NAME",
+		Short: "Show a Konflux ReleasePlan",
+		Long: `Show a Konflux ReleasePlan: application, target, auto-release setting, the matched ReleasePlanAdmission,
+or why it is not matched, and the last Releases created from the plan with their outcomes`,
+		Args: cobra.ExactArgs(1),
+		RunE: runGet,
+	}
+
+	cmd.Flags().StringVarP(&getNamespace, "namespace", "n", "", "Tenant namespace of the ReleasePlan (default the namespace of the current kubeconfig context)")
+	cmd.Flags().IntVar(&getReleases, "releases", 5, "Number of last Releases shown")
+	getOutput.AddFlags(cmd)
+
+	return cmd
+}
+
+func runGet(cmd *cobra.Command, args []string) error {
+	printer, err := getOutput.Printer()
+	if err != nil {
+		return err
+	}
+
+	if getReleases < 0 {
+		return fmt.Errorf("--releases must not be negative, got %d", getReleases)
+	}
+
+	namespace, err := kube.Namespace(getNamespace)
+	if err != nil {
+		return err
+	}
+
+	rawClient, err := kube.NewClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	// releases share their release plan, fetch it once
+	k8sClient := kube.NewCachingClient(rawClient)
+	defer k8sClient.LogStats()
+
+	releasePlan, err := metadata.GetReleasePlan(cmd.Context(), k8sClient, namespace, args[0], getReleases)
+	if err != nil {
+		return err
+	}
+
+	return printer.Print(cmd.OutOrStdout(), releasePlan)
+}
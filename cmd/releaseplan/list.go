@@ -0,0 +1,56 @@
+package releaseplan
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/eguzki/konfluxctl/internal/kube"
+	"github.com/eguzki/konfluxctl/internal/metadata"
+	"github.com/eguzki/konfluxctl/internal/output"
+)
+
+//konfluxctl releaseplan list [--application APP]
+
+var (
+	listNamespaces  []string
+	listApplication string
+	listOutput      = output.NewFlags(output.FormatTable, output.FormatWide, output.FormatName)
+)
+
+func ListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List Konflux ReleasePlans",
+		Long:  "List Konflux ReleasePlans with their match status and the outcome of their last Release",
+		Args:  cobra.NoArgs,
+		RunE:  runList,
+	}
+
+	cmd.Flags().StringArrayVarP(&listNamespaces, "namespace", "n", nil, "Tenant namespace where ReleasePlans are looked up. Repeatable (default all namespaces the user can read)")
+	cmd.Flags().StringVar(&listApplication, "application", "", "Only release plans of the application")
+	listOutput.AddFlags(cmd)
+
+	return cmd
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	printer, err := listOutput.Printer()
+	if err != nil {
+		return err
+	}
+
+	rawClient, err := kube.NewClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	// releases share their release plans, fetch them once
+	k8sClient := kube.NewCachingClient(rawClient)
+	defer k8sClient.LogStats()
+
+	releasePlans, err := metadata.ListReleasePlans(cmd.Context(), k8sClient, listApplication, 1, listNamespaces)
+	if err != nil {
+		return err
+	}
+
+	return printer.Print(cmd.OutOrStdout(), releasePlans)
+}
@@ -16,16 +16,47 @@ limitations under the License.
 package cmd
 
 import (
-	"context"
 	"log/slog"
+	"os"
 
 	"github.com/spf13/cobra"
+
+	"github.com/eguzki/konfluxctl/internal/config"
+	"github.com/eguzki/konfluxctl/internal/kube"
 )
 
 var (
-	verbose bool
+	verbose    bool
+	configFile string
+	fromDirs   []string
+	fromFiles  []string
+	recordFile string
+	replayFile string
+	// recordOut is the open --record file, closed when the command finishes
+	recordOut *os.File
 )
 
+func init() {
+	// finalizers run once the command finishes, failing or not: PersistentPostRunE is skipped on errors
+	cobra.OnFinalize(closeRecording)
+}
+
+// closeRecording flushes the --record file to disk and closes it
+func closeRecording() {
+	if recordOut == nil {
+		return
+	}
+	out := recordOut
+	recordOut = nil
+
+	if err := out.Sync(); err != nil {
+		slog.Error("syncing recording", "file", out.Name(), "error", err)
+	}
+	if err := out.Close(); err != nil {
+		slog.Error("closing recording", "file", out.Name(), "error", err)
+	}
+}
+
 // GetRootCmd returns the root of the cobra command-tree.
 func GetRootCmd(args []string) *cobra.Command {
 	// rootCmd represents the base command when called without any subcommands
@@ -33,13 +64,47 @@ func GetRootCmd(args []string) *cobra.Command {
 		Use:   "konfluxctl",
 		Short: "konflux command line utility",
 		Long:  "konflux command line utility",
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			logLevel := slog.LevelInfo
 			if verbose {
 				logLevel = slog.LevelDebug
 			}
 			slog.SetLogLoggerLevel(logLevel)
-			cmd.SetContext(context.Background())
+
+			cfg, err := config.Load(configFile)
+			if err != nil {
+				return err
+			}
+			ctx := config.WithConfig(cmd.Context(), cfg)
+
+			if len(fromDirs) > 0 || len(fromFiles) > 0 {
+				offlineClient, err := kube.NewOfflineClient(fromDirs, fromFiles)
+				if err != nil {
+					return err
+				}
+				ctx = kube.WithOfflineClient(ctx, offlineClient)
+			}
+
+			if replayFile != "" {
+				replayClient, err := kube.NewReplayClient(replayFile)
+				if err != nil {
+					return err
+				}
+				ctx = kube.WithOfflineClient(ctx, replayClient)
+			}
+
+			if recordFile != "" {
+				// interactions are written as they happen, the file is closed by closeRecording
+				out, err := os.Create(recordFile)
+				if err != nil {
+					return err
+				}
+				recordOut = out
+				ctx = kube.WithRecording(ctx, out)
+			}
+
+			cmd.SetContext(ctx)
+			return nil
 		},
 	}
 
@@ -48,9 +113,25 @@ func GetRootCmd(args []string) *cobra.Command {
 	// avoid usage being shown on error
 	rootCmd.SilenceUsage = true
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "config file (default $KONFLUXCTL_CONFIG or $XDG_CONFIG_HOME/konfluxctl/config.yaml)")
+	rootCmd.PersistentFlags().StringArrayVar(&fromDirs, "from-dir", nil, "offline mode: read objects from the YAML and JSON manifests of the directory instead of the cluster. Repeatable")
+	rootCmd.PersistentFlags().StringArrayVar(&fromFiles, "from-file", nil, "offline mode: read objects from the YAML manifest file (multi-document or kind: List), or the .tar.gz export, instead of the cluster. Repeatable")
+	rootCmd.PersistentFlags().StringVar(&recordFile, "record", "", "record every Kubernetes API read, with its response, to the file")
+	rootCmd.PersistentFlags().StringVar(&replayFile, "replay", "", "serve Kubernetes API reads from a file written by --record instead of the cluster. Images must be passed by digest")
+	rootCmd.MarkFlagsMutuallyExclusive("record", "replay")
+	rootCmd.MarkFlagsMutuallyExclusive("replay", "from-dir")
+	rootCmd.MarkFlagsMutuallyExclusive("replay", "from-file")
 
 	rootCmd.AddCommand(versionCommand())
 	rootCmd.AddCommand(imageCommand())
+	rootCmd.AddCommand(releaseCommand())
+	rootCmd.AddCommand(releasePlanCommand())
+	rootCmd.AddCommand(rpaCommand())
+	rootCmd.AddCommand(snapshotCommand())
+	rootCmd.AddCommand(sourceCommand())
+	rootCmd.AddCommand(lintCommand())
+	rootCmd.AddCommand(exportCommand())
+	rootCmd.AddCommand(cacheCommand())
 
 	return rootCmd
 }
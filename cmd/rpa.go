@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/eguzki/konfluxctl/cmd/rpa"
+	"github.com/spf13/cobra"
+)
+
+func rpaCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rpa",
+		Short: "Konflux ReleasePlanAdmission related utility",
+		Long:  "Konflux ReleasePlanAdmission related utility",
+	}
+
+	cmd.AddCommand(rpa.ListCommand())
+	cmd.AddCommand(rpa.GetCommand())
+	cmd.AddCommand(rpa.FindCommand())
+	return cmd
+}
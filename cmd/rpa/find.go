@@ -0,0 +1,65 @@
+package rpa
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eguzki/konfluxctl/internal/kube"
+	"github.com/eguzki/konfluxctl/internal/metadata"
+	"github.com/eguzki/konfluxctl/internal/output"
+)
+
+//konfluxctl rpa find --repository REPOSITORY
+
+var (
+	findRepository    string
+	findNamespaces    []string
+	findAllNamespaces bool
+	findOutput        = output.NewFlags(output.FormatTable, output.FormatWide, output.FormatName)
+)
+
+func FindCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "find",
+		Short: "Find the Konflux ReleasePlanAdmissions publishing to a repository",
+		Long:  "Find the Konflux ReleasePlanAdmissions with a component mapped to the repository. Tags and digests are ignored",
+		Args:  cobra.NoArgs,
+		RunE:  runFind,
+	}
+
+	cmd.Flags().StringVar(&findRepository, "repository", "", "Repository, e.g. quay.io/org/img (required)")
+	cmd.Flags().StringArrayVarP(&findNamespaces, "namespace", "n", nil, "Namespace where ReleasePlanAdmissions are looked up. Repeatable (default from config file or \""+metadata.DefaultReleasePlanAdmissionNamespace+"\")")
+	cmd.Flags().BoolVar(&findAllNamespaces, "all-namespaces", false, "Look up ReleasePlanAdmissions across all namespaces the user can read")
+	cmd.MarkFlagsMutuallyExclusive("namespace", "all-namespaces")
+	findOutput.AddFlags(cmd)
+
+	if err := cmd.MarkFlagRequired("repository"); err != nil {
+		fmt.Println("Error setting 'repository' flag as required:", err)
+		os.Exit(1)
+	}
+
+	return cmd
+}
+
+func runFind(cmd *cobra.Command, args []string) error {
+	printer, err := findOutput.Printer()
+	if err != nil {
+		return err
+	}
+
+	k8sClient, err := kube.NewClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	namespaces := rpaNamespaces(cmd.Context(), findNamespaces, findAllNamespaces)
+
+	rpas, err := metadata.FindReleasePlanAdmissionsByRepository(cmd.Context(), k8sClient, findRepository, namespaces)
+	if err != nil {
+		return err
+	}
+
+	return printer.Print(cmd.OutOrStdout(), rpas)
+}
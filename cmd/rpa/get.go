@@ -0,0 +1,56 @@
+package rpa
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/eguzki/konfluxctl/internal/kube"
+	"github.com/eguzki/konfluxctl/internal/metadata"
+	"github.com/eguzki/konfluxctl/internal/output"
+)
+
+//konfluxctl rpa get NAME
+
+var (
+	getNamespace string
+	getOutput    = output.NewFlags(output.FormatText, output.FormatTable, output.FormatWide, output.FormatName)
+)
+
+func GetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get NAME",
+		Short: "Show a Konflux ReleasePlanAdmission",
+		Long: `Show a Konflux ReleasePlanAdmission: the component to repository to tags mapping,
+the matched ReleasePlans, the policy and the pipeline`,
+		Args: cobra.ExactArgs(1),
+		RunE: runGet,
+	}
+
+	cmd.Flags().StringVarP(&getNamespace, "namespace", "n", "", "Namespace of the ReleasePlanAdmission (default the first one from config file or \""+metadata.DefaultReleasePlanAdmissionNamespace+"\")")
+	getOutput.AddFlags(cmd)
+
+	return cmd
+}
+
+func runGet(cmd *cobra.Command, args []string) error {
+	printer, err := getOutput.Printer()
+	if err != nil {
+		return err
+	}
+
+	namespace, err := rpaNamespace(cmd.Context(), getNamespace)
+	if err != nil {
+		return err
+	}
+
+	k8sClient, err := kube.NewClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	rpa, err := metadata.GetReleasePlanAdmission(cmd.Context(), k8sClient, namespace, args[0])
+	if err != nil {
+		return err
+	}
+
+	return printer.Print(cmd.OutOrStdout(), rpa)
+}
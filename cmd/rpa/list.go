@@ -0,0 +1,55 @@
+package rpa
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/eguzki/konfluxctl/internal/kube"
+	"github.com/eguzki/konfluxctl/internal/metadata"
+	"github.com/eguzki/konfluxctl/internal/output"
+)
+
+//konfluxctl rpa list
+
+var (
+	listNamespaces    []string
+	listAllNamespaces bool
+	listOutput        = output.NewFlags(output.FormatTable, output.FormatWide, output.FormatName)
+)
+
+func ListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List Konflux ReleasePlanAdmissions",
+		Long:  "List Konflux ReleasePlanAdmissions with their origin, applications and policy",
+		Args:  cobra.NoArgs,
+		RunE:  runList,
+	}
+
+	cmd.Flags().StringArrayVarP(&listNamespaces, "namespace", "n", nil, "Namespace where ReleasePlanAdmissions are looked up. Repeatable (default from config file or \""+metadata.DefaultReleasePlanAdmissionNamespace+"\")")
+	cmd.Flags().BoolVar(&listAllNamespaces, "all-namespaces", false, "Look up ReleasePlanAdmissions across all namespaces the user can read")
+	cmd.MarkFlagsMutuallyExclusive("namespace", "all-namespaces")
+	listOutput.AddFlags(cmd)
+
+	return cmd
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	printer, err := listOutput.Printer()
+	if err != nil {
+		return err
+	}
+
+	k8sClient, err := kube.NewClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	namespaces := rpaNamespaces(cmd.Context(), listNamespaces, listAllNamespaces)
+
+	rpas, err := metadata.ListReleasePlanAdmissions(cmd.Context(), k8sClient, namespaces)
+	if err != nil {
+		return err
+	}
+
+	return printer.Print(cmd.OutOrStdout(), rpas)
+}
@@ -0,0 +1,28 @@
+package rpa
+
+import (
+	"context"
+	"errors"
+
+	"github.com/eguzki/konfluxctl/internal/config"
+	"github.com/eguzki/konfluxctl/internal/metadata"
+)
+
+// rpaNamespaces resolves where ReleasePlanAdmissions are listed, like `image metadata` does.
+// An empty result means all namespaces.
+func rpaNamespaces(ctx context.Context, flagNamespaces []string, flagAllNamespaces bool) []string {
+	return config.FromContext(ctx).ReleasePlanAdmissionNamespaces(flagNamespaces, flagAllNamespaces, metadata.DefaultReleasePlanAdmissionNamespace)
+}
+
+// rpaNamespace returns the namespace flag value, or the first configured ReleasePlanAdmission namespace when unset
+func rpaNamespace(ctx context.Context, flagNamespace string) (string, error) {
+	if flagNamespace != "" {
+		return flagNamespace, nil
+	}
+
+	namespaces := rpaNamespaces(ctx, nil, false)
+	if len(namespaces) == 0 {
+		return "", errors.New("--namespace is required when ReleasePlanAdmissions are looked up across all namespaces")
+	}
+	return namespaces[0], nil
+}
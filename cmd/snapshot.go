@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/eguzki/konfluxctl/cmd/snapshot"
+	"github.com/spf13/cobra"
+)
+
+func snapshotCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Konflux Snapshot related utility",
+		Long:  "Konflux Snapshot related utility",
+	}
+
+	cmd.AddCommand(snapshot.ListCommand())
+	cmd.AddCommand(snapshot.GetCommand())
+	cmd.AddCommand(snapshot.FindCommand())
+	cmd.AddCommand(snapshot.DiffCommand())
+	return cmd
+}
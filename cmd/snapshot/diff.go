@@ -0,0 +1,84 @@
+package snapshot
+
+import (
+	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/eguzki/konfluxctl/internal/kube"
+	"github.com/eguzki/konfluxctl/internal/metadata"
+	"github.com/eguzki/konfluxctl/internal/output"
+)
+
+//konfluxctl snapshot diff FROM TO
+//konfluxctl snapshot diff --last-released TO
+
+var (
+	diffNamespace    string
+	diffLastReleased bool
+	diffOutput       = output.NewFlags(output.FormatText)
+)
+
+func DiffCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff FROM TO",
+		Short: "Compare two Konflux Snapshots component by component",
+		Long: `Compare two Konflux Snapshots component by component: added and removed components,
+image digest and git revision changes. With --last-released, TO is compared with the snapshot
+of the latest Released release of the same application`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if diffLastReleased {
+				return cobra.ExactArgs(1)(cmd, args)
+			}
+			return cobra.ExactArgs(2)(cmd, args)
+		},
+		RunE: runDiff,
+	}
+
+	cmd.Flags().StringVarP(&diffNamespace, "namespace", "n", "", "Tenant namespace of the Snapshots (default the namespace of the current kubeconfig context)")
+	cmd.Flags().BoolVar(&diffLastReleased, "last-released", false, "Compare with the snapshot of the latest Released release of the same application")
+	diffOutput.AddFlags(cmd)
+
+	return cmd
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	printer, err := diffOutput.Printer()
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+
+	namespace, err := kube.Namespace(diffNamespace)
+	if err != nil {
+		return err
+	}
+
+	k8sClient, err := kube.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	to := &applicationapi.Snapshot{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: args[len(args)-1]}, to); err != nil {
+		return err
+	}
+
+	fromName := args[0]
+	if diffLastReleased {
+		fromName, err = metadata.LastReleasedSnapshot(ctx, k8sClient, namespace, to.Spec.Application, to.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	from := &applicationapi.Snapshot{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: fromName}, from); err != nil {
+		return err
+	}
+
+	diff := metadata.DiffSnapshots(from, to)
+
+	return printer.Print(cmd.OutOrStdout(), diff)
+}
@@ -0,0 +1,87 @@
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eguzki/konfluxctl/internal/kube"
+	"github.com/eguzki/konfluxctl/internal/metadata"
+	"github.com/eguzki/konfluxctl/internal/output"
+	"github.com/eguzki/konfluxctl/internal/registry"
+	"github.com/eguzki/konfluxctl/internal/utils"
+)
+
+//konfluxctl snapshot find --image IMAGE_URL
+
+var (
+	findImageURL       string
+	findNamespaces     []string
+	findMatchPlatforms bool
+	findOutput         = output.NewFlags(output.FormatTable, output.FormatWide, output.FormatName)
+)
+
+func FindCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "find",
+		Short: "Find the Konflux Snapshots containing an image",
+		Long:  "Find the Konflux Snapshots with a component built as the image, matched by digest",
+		Args:  cobra.NoArgs,
+		RunE:  runFind,
+	}
+
+	cmd.Flags().StringVar(&findImageURL, "image", "", "Docker/OCI image URL, by digest or by tag (required)")
+	cmd.Flags().StringArrayVarP(&findNamespaces, "namespace", "n", nil, "Tenant namespace where Snapshots are looked up. Repeatable (default all namespaces the user can read)")
+	cmd.Flags().BoolVar(&findMatchPlatforms, "match-platforms", false, "Match multi-arch image indexes with their platform manifests. Fetches the image index of every component from the registry")
+	findOutput.AddFlags(cmd)
+
+	if err := cmd.MarkFlagRequired("image"); err != nil {
+		fmt.Println("Error setting 'image' flag as required:", err)
+		os.Exit(1)
+	}
+
+	return cmd
+}
+
+func runFind(cmd *cobra.Command, args []string) error {
+	printer, err := findOutput.Printer()
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+
+	// replays are hermetic, only images pinned by digest resolve
+	resolver := registry.NewResolver(registry.WithNetwork(!kube.IsReplaying(ctx)))
+	if findMatchPlatforms {
+		// offline and replayed runs read nothing but their inputs
+		if kube.IsOffline(ctx) {
+			return errors.New("--match-platforms fetches image indexes from the registry, it is not supported offline")
+		}
+		ctx = metadata.WithManifestIndex(ctx, resolver)
+	}
+
+	pinnedImageURL, err := resolver.Resolve(ctx, findImageURL)
+	if err != nil {
+		return err
+	}
+
+	imageRef, err := utils.ParseImageURL(pinnedImageURL)
+	if err != nil {
+		return err
+	}
+
+	k8sClient, err := kube.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := metadata.FindSnapshotsByImage(ctx, k8sClient, imageRef, findNamespaces)
+	if err != nil {
+		return err
+	}
+
+	return printer.Print(cmd.OutOrStdout(), snapshots)
+}
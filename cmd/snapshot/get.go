@@ -0,0 +1,55 @@
+package snapshot
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/eguzki/konfluxctl/internal/kube"
+	"github.com/eguzki/konfluxctl/internal/metadata"
+	"github.com/eguzki/konfluxctl/internal/output"
+)
+
+//konfluxctl snapshot get NAME
+
+var (
+	getNamespace string
+	getOutput    = output.NewFlags(output.FormatText, output.FormatTable, output.FormatWide, output.FormatName)
+)
+
+func GetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get NAME",
+		Short: "Show a Konflux Snapshot",
+		Long:  "Show a Konflux Snapshot with its components: images, git URLs and revisions",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runGet,
+	}
+
+	cmd.Flags().StringVarP(&getNamespace, "namespace", "n", "", "Tenant namespace of the Snapshot (default the namespace of the current kubeconfig context)")
+	getOutput.AddFlags(cmd)
+
+	return cmd
+}
+
+func runGet(cmd *cobra.Command, args []string) error {
+	printer, err := getOutput.Printer()
+	if err != nil {
+		return err
+	}
+
+	namespace, err := kube.Namespace(getNamespace)
+	if err != nil {
+		return err
+	}
+
+	k8sClient, err := kube.NewClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := metadata.GetSnapshot(cmd.Context(), k8sClient, namespace, args[0])
+	if err != nil {
+		return err
+	}
+
+	return printer.Print(cmd.OutOrStdout(), snapshot)
+}
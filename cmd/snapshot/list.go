@@ -0,0 +1,52 @@
+package snapshot
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/eguzki/konfluxctl/internal/kube"
+	"github.com/eguzki/konfluxctl/internal/metadata"
+	"github.com/eguzki/konfluxctl/internal/output"
+)
+
+//konfluxctl snapshot list [--application APP]
+
+var (
+	listNamespaces  []string
+	listApplication string
+	listOutput      = output.NewFlags(output.FormatTable, output.FormatWide, output.FormatName)
+)
+
+func ListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List Konflux Snapshots",
+		Long:  "List Konflux Snapshots, most recently created first",
+		Args:  cobra.NoArgs,
+		RunE:  runList,
+	}
+
+	cmd.Flags().StringArrayVarP(&listNamespaces, "namespace", "n", nil, "Tenant namespace where Snapshots are looked up. Repeatable (default all namespaces the user can read)")
+	cmd.Flags().StringVar(&listApplication, "application", "", "Only snapshots of the application")
+	listOutput.AddFlags(cmd)
+
+	return cmd
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	printer, err := listOutput.Printer()
+	if err != nil {
+		return err
+	}
+
+	k8sClient, err := kube.NewClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := metadata.ListSnapshots(cmd.Context(), k8sClient, listApplication, listNamespaces)
+	if err != nil {
+		return err
+	}
+
+	return printer.Print(cmd.OutOrStdout(), snapshots)
+}
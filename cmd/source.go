@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/eguzki/konfluxctl/cmd/source"
+	"github.com/spf13/cobra"
+)
+
+func sourceCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "source",
+		Short: "Source code related utility",
+		Long:  "Source code related utility",
+	}
+
+	cmd.AddCommand(source.ReleasedCommand())
+	return cmd
+}
@@ -0,0 +1,75 @@
+package source
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eguzki/konfluxctl/internal/kube"
+	"github.com/eguzki/konfluxctl/internal/metadata"
+	"github.com/eguzki/konfluxctl/internal/output"
+)
+
+//konfluxctl source released --url GIT_URL --revision SHA
+
+var (
+	sourceURL        string
+	sourceRevision   string
+	sourceNamespaces []string
+	releasedOutput   = output.NewFlags(output.FormatText, output.FormatTable, output.FormatWide)
+)
+
+func ReleasedCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "released",
+		Short: "Returns the images released from a source revision",
+		Long:  "Returns the images released from a source revision: published repository, tags and advisory of every release",
+		RunE:  runReleased,
+	}
+
+	cmd.Flags().StringVar(&sourceURL, "url", "", "Git repository URL (required)")
+	cmd.Flags().StringVar(&sourceRevision, "revision", "", "Git commit SHA, abbreviated SHAs are accepted (required)")
+	cmd.Flags().StringArrayVarP(&sourceNamespaces, "namespace", "n", nil, "Tenant namespace where Snapshots are looked up. Repeatable (default all namespaces the user can read)")
+	releasedOutput.AddFlags(cmd)
+
+	for _, flag := range []string{"url", "revision"} {
+		if err := cmd.MarkFlagRequired(flag); err != nil {
+			fmt.Printf("Error setting '%s' flag as required: %s\n", flag, err)
+			os.Exit(1)
+		}
+	}
+
+	return cmd
+}
+
+func runReleased(cmd *cobra.Command, args []string) error {
+	printer, err := releasedOutput.Printer()
+	if err != nil {
+		return err
+	}
+
+	rawClient, err := kube.NewClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	// releases and release plans are shared by many snapshots, fetch them once
+	k8sClient := kube.NewCachingClient(rawClient)
+	defer k8sClient.LogStats()
+
+	images, err := metadata.SourceReleasedImages(cmd.Context(), k8sClient, sourceURL, sourceRevision, sourceNamespaces)
+	if err != nil {
+		return err
+	}
+
+	slog.Debug("source released", "images", len(images))
+
+	if len(images) == 0 {
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "🧐 No released image found")
+		return nil
+	}
+
+	return printer.Print(cmd.OutOrStdout(), images)
+}
@@ -0,0 +1,313 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eguzki/konfluxctl/internal/metadata"
+	"github.com/eguzki/konfluxctl/internal/output"
+)
+
+// DefaultTTL is the default lifetime of cached records. Lineage of released images
+// does not change, the TTL only bounds the cache size.
+const DefaultTTL = 7 * 24 * time.Hour
+
+const recordExt = ".json"
+
+// Record is the resolved lineage of one image on one cluster
+type Record struct {
+	Key     string `json:"key"`
+	Cluster string `json:"cluster"`
+	Image   string `json:"image"`
+	// Namespaces where ReleasePlanAdmissions were looked up, empty for all readable namespaces
+	Namespaces []string        `json:"namespaces,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	ExpiresAt  time.Time       `json:"expiresAt"`
+	Paths      []metadata.Path `json:"paths"`
+}
+
+func (r Record) Expired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+func (r Record) String() string {
+	return fmt.Sprintf(`Key: %s
+Cluster: %s
+Image: %s
+Namespaces: %s
+Created: %s
+Expires: %s
+
+%s`,
+		r.Key,
+		r.Cluster,
+		r.Image,
+		namespacesString(r.Namespaces),
+		r.CreatedAt.Format(time.RFC3339),
+		r.ExpiresAt.Format(time.RFC3339),
+		metadata.PathList(r.Paths),
+	)
+}
+
+// RecordList is the result of Store.List
+type RecordList []Record
+
+func (l RecordList) Table() *output.Table {
+	now := time.Now()
+	rows := make([][]string, 0, len(l))
+	for _, record := range l {
+		status := "valid"
+		if record.Expired(now) {
+			status = "expired"
+		}
+		rows = append(rows, []string{shortKey(record.Key), record.Image, record.Cluster, fmt.Sprint(len(record.Paths)),
+			record.CreatedAt.Format(time.RFC3339), status, namespacesString(record.Namespaces), record.ExpiresAt.Format(time.RFC3339)})
+	}
+
+	return &output.Table{
+		Columns: []output.Column{{Name: "KEY"}, {Name: "IMAGE"}, {Name: "CLUSTER"}, {Name: "PATHS"}, {Name: "CREATED"},
+			{Name: "STATUS"}, {Name: "NAMESPACES", Wide: true}, {Name: "EXPIRES", Wide: true}},
+		Rows: rows,
+	}
+}
+
+// shortKey is the key prefix shown in tables, records written by hand may have shorter keys
+func shortKey(key string) string {
+	if len(key) > 12 {
+		return key[:12]
+	}
+	return key
+}
+
+func namespacesString(namespaces []string) string {
+	if len(namespaces) == 0 {
+		return "*"
+	}
+	return strings.Join(namespaces, ",")
+}
+
+// Names are the record keys, see Store.Find
+func (l RecordList) Names() []string {
+	keys := make([]string, 0, len(l))
+	for _, record := range l {
+		keys = append(keys, record.Key)
+	}
+	return keys
+}
+
+// Store keeps records on disk, one file per record
+type Store struct {
+	dir string
+	now func() time.Time
+}
+
+// DefaultDir returns $XDG_CACHE_HOME/konfluxctl/lineage
+func DefaultDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "konfluxctl", "lineage"), nil
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir, now: time.Now}
+}
+
+// Key identifies the lineage of an image on a cluster, resolved from the ReleasePlanAdmissions
+// of the namespaces. No namespace means all the namespaces the user can read.
+func Key(cluster, image string, namespaces []string) string {
+	sum := sha256.Sum256([]byte(cluster + "\x00" + image + "\x00" + namespacesString(normalizeNamespaces(namespaces))))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeNamespaces sorts and dedupes the namespaces, the order they were given in does not change the lineage
+func normalizeNamespaces(namespaces []string) []string {
+	if len(namespaces) == 0 {
+		return nil
+	}
+	normalized := slices.Clone(namespaces)
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+// Get returns the record when it exists and has not expired
+func (s *Store) Get(cluster, image string, namespaces []string) (*Record, bool, error) {
+	record, err := s.read(filepath.Join(s.dir, Key(cluster, image, namespaces)+recordExt))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if record.Expired(s.now()) {
+		return nil, false, nil
+	}
+
+	return record, true, nil
+}
+
+func (s *Store) Put(cluster, image string, namespaces []string, paths []metadata.Path, ttl time.Duration) error {
+	now := s.now()
+	record := Record{
+		Key:        Key(cluster, image, namespaces),
+		Cluster:    cluster,
+		Image:      image,
+		Namespaces: normalizeNamespaces(namespaces),
+		CreatedAt:  now.UTC(),
+		ExpiresAt:  now.Add(ttl).UTC(),
+		Paths:      paths,
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+
+	// write and rename, concurrent readers never see partial records
+	tmp, err := os.CreateTemp(s.dir, record.Key+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(s.dir, record.Key+recordExt))
+}
+
+// List returns every record, including the expired ones, sorted by creation time.
+// Unreadable records are skipped, Purge deletes them.
+func (s *Store) List() (RecordList, error) {
+	records, _, err := s.scan()
+	return records, err
+}
+
+// scan reads every record file, returning the records and the files that could not be read
+func (s *Store) scan() (RecordList, []string, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*"+recordExt))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	records := RecordList{}
+	var unreadable []string
+	for _, file := range files {
+		record, err := s.read(file)
+		// deleted by a concurrent purge
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			slog.Debug("cache record skipped", "file", file, "error", err)
+			unreadable = append(unreadable, file)
+			continue
+		}
+		records = append(records, *record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.Before(records[j].CreatedAt)
+	})
+
+	return records, unreadable, nil
+}
+
+// Find returns the record whose key starts with the given prefix
+func (s *Store) Find(keyPrefix string) (*Record, error) {
+	if keyPrefix == "" {
+		return nil, errors.New("empty cache key")
+	}
+
+	records, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var found *Record
+	for idx := range records {
+		if !strings.HasPrefix(records[idx].Key, keyPrefix) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("cache key prefix %q is ambiguous", keyPrefix)
+		}
+		found = &records[idx]
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("cache record %q not found", keyPrefix)
+	}
+
+	return found, nil
+}
+
+func (s *Store) Delete(key string) error {
+	return os.Remove(filepath.Join(s.dir, key+recordExt))
+}
+
+// Purge deletes records, only the expired ones when expiredOnly is set.
+// Unreadable records are always deleted. Returns the number of deleted records.
+func (s *Store) Purge(expiredOnly bool) (int, error) {
+	records, unreadable, err := s.scan()
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, file := range unreadable {
+		if err := os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	now := s.now()
+	for _, record := range records {
+		if expiredOnly && !record.Expired(now) {
+			continue
+		}
+		if err := s.Delete(record.Key); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+func (s *Store) read(file string) (*Record, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &Record{}
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, fmt.Errorf("parsing cache record %s: %w", file, err)
+	}
+
+	return record, nil
+}
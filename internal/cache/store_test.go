@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+
+	"github.com/eguzki/konfluxctl/internal/metadata"
+)
+
+var _ = Describe("Store", func() {
+	const (
+		cluster = "https://api.cluster.example.com:6443"
+		image   = "quay.io/org/img@sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	)
+
+	var (
+		store *Store
+		now   time.Time
+		paths []metadata.Path
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2025, time.May, 1, 10, 0, 0, 0, time.UTC)
+		store = NewStore(GinkgoT().TempDir())
+		store.now = func() time.Time { return now }
+		paths = []metadata.Path{{Release: ptr.To("my-release")}}
+	})
+
+	It("returns stored records until they expire", func() {
+		_, ok, err := store.Get(cluster, image, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+
+		Expect(store.Put(cluster, image, nil, paths, time.Hour)).To(Succeed())
+
+		record, ok, err := store.Get(cluster, image, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(record.Paths).To(Equal(paths))
+
+		_, ok, err = store.Get("https://other-cluster", image, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+
+		now = now.Add(time.Hour)
+		_, ok, err = store.Get(cluster, image, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+
+	It("keys records by ReleasePlanAdmission namespaces", func() {
+		Expect(store.Put(cluster, image, []string{"rpa-b", "rpa-a"}, paths, time.Hour)).To(Succeed())
+
+		record, ok, err := store.Get(cluster, image, []string{"rpa-a", "rpa-b", "rpa-a"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(record.Namespaces).To(Equal([]string{"rpa-a", "rpa-b"}))
+
+		for _, namespaces := range [][]string{nil, {"rpa-a"}} {
+			_, ok, err = store.Get(cluster, image, namespaces)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		}
+	})
+
+	It("lists records with short keys", func() {
+		Expect(store.Put(cluster, image, nil, paths, time.Hour)).To(Succeed())
+		records, err := store.List()
+		Expect(err).ToNot(HaveOccurred())
+		records[0].Key = "abc"
+		Expect(records.Table().Rows[0][0]).To(Equal("abc"))
+	})
+
+	It("finds records by key prefix", func() {
+		Expect(store.Put(cluster, image, nil, paths, time.Hour)).To(Succeed())
+		record, err := store.Find(Key(cluster, image, nil)[:12])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(record.Image).To(Equal(image))
+
+		_, err = store.Find("zzz")
+		Expect(err).To(MatchError(ContainSubstring("not found")))
+	})
+
+	It("purges expired records", func() {
+		Expect(store.Put(cluster, image, nil, paths, time.Hour)).To(Succeed())
+		Expect(store.Put(cluster, image+"-other", nil, paths, 3*time.Hour)).To(Succeed())
+
+		now = now.Add(2 * time.Hour)
+		deleted, err := store.Purge(true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deleted).To(Equal(1))
+
+		records, err := store.List()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(records).To(HaveLen(1))
+
+		deleted, err = store.Purge(false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deleted).To(Equal(1))
+	})
+
+	It("skips corrupt records when listing and purges them", func() {
+		Expect(store.Put(cluster, image, nil, paths, time.Hour)).To(Succeed())
+		corrupt := filepath.Join(store.dir, "corrupt"+recordExt)
+		Expect(os.WriteFile(corrupt, []byte("{not json"), 0o600)).To(Succeed())
+
+		records, err := store.List()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(records.Names()).To(Equal([]string{Key(cluster, image, nil)}))
+
+		record, err := store.Find(Key(cluster, image, nil)[:12])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(record.Image).To(Equal(image))
+
+		deleted, err := store.Purge(true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deleted).To(Equal(1))
+		Expect(corrupt).ToNot(BeAnExistingFile())
+
+		records, err = store.List()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(records).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+)
+
+// EnvVar overrides the default location of the configuration file
+const EnvVar = "KONFLUXCTL_CONFIG"
+
+// Config holds the user defaults read from the konfluxctl configuration file
+//
+// Example:
+//
+//	rpaNamespaces:
+//	  - managed-release-team
+//	  - rhtap-releng-tenant
+//	rpaAllNamespaces: false
+type Config struct {
+	// RPANamespaces is the list of namespaces where ReleasePlanAdmissions are looked up
+	RPANamespaces []string `json:"rpaNamespaces,omitempty"`
+	// RPAAllNamespaces enables looking up ReleasePlanAdmissions across all namespaces
+	RPAAllNamespaces bool `json:"rpaAllNamespaces,omitempty"`
+}
+
+// DefaultPath returns the configuration file location:
+// $KONFLUXCTL_CONFIG or $XDG_CONFIG_HOME/konfluxctl/config.yaml
+func DefaultPath() (string, error) {
+	if path, ok := os.LookupEnv(EnvVar); ok && path != "" {
+		return path, nil
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "konfluxctl", "config.yaml"), nil
+}
+
+// Load reads the configuration file. When the path is empty, the default location is used
+// and a missing file is not an error.
+func Load(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		defaultPath, err := DefaultPath()
+		if err != nil {
+			return &Config{}, nil
+		}
+		path = defaultPath
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	cfg := &Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// ReleasePlanAdmissionNamespaces resolves where ReleasePlanAdmissions are looked up.
+// Command line values take precedence over the config file, which takes precedence
+// over the default namespace. An empty result means all namespaces.
+func (c *Config) ReleasePlanAdmissionNamespaces(flagNamespaces []string, flagAllNamespaces bool, defaultNamespace string) []string {
+	if flagAllNamespaces {
+		return nil
+	}
+
+	if len(flagNamespaces) > 0 {
+		return flagNamespaces
+	}
+
+	if c.RPAAllNamespaces {
+		return nil
+	}
+
+	if len(c.RPANamespaces) > 0 {
+		return c.RPANamespaces
+	}
+
+	return []string{defaultNamespace}
+}
+
+type contextKey struct{}
+
+// WithConfig returns a copy of the context carrying the configuration
+func WithConfig(ctx context.Context, cfg *Config) context.Context {
+	return context.WithValue(ctx, contextKey{}, cfg)
+}
+
+// FromContext returns the configuration carried by the context or an empty one
+func FromContext(ctx context.Context) *Config {
+	if cfg, ok := ctx.Value(contextKey{}).(*Config); ok && cfg != nil {
+		return cfg
+	}
+	return &Config{}
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Load", func() {
+	It("reads the given file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "config.yaml")
+		Expect(os.WriteFile(path, []byte("rpaNamespaces:\n- managed-release-team\n"), 0o600)).To(Succeed())
+		cfg, err := Load(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.RPANamespaces).To(Equal([]string{"managed-release-team"}))
+	})
+
+	It("fails when the given file does not exist", func() {
+		_, err := Load(filepath.Join(GinkgoT().TempDir(), "missing.yaml"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("ignores a missing default file", func() {
+		GinkgoT().Setenv(EnvVar, "")
+		GinkgoT().Setenv("XDG_CONFIG_HOME", GinkgoT().TempDir())
+		cfg, err := Load("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg).To(Equal(&Config{}))
+	})
+})
+
+var _ = DescribeTable("ReleasePlanAdmissionNamespaces",
+	func(cfg Config, flagNamespaces []string, flagAll bool, expected []string) {
+		Expect(cfg.ReleasePlanAdmissionNamespaces(flagNamespaces, flagAll, "default-ns")).To(Equal(expected))
+	},
+	Entry("default", Config{}, nil, false, []string{"default-ns"}),
+	Entry("config file", Config{RPANamespaces: []string{"a", "b"}}, nil, false, []string{"a", "b"}),
+	Entry("config file all namespaces", Config{RPAAllNamespaces: true}, nil, false, nil),
+	Entry("flags take precedence", Config{RPANamespaces: []string{"a"}}, []string{"c"}, false, []string{"c"}),
+	Entry("all namespaces flag", Config{RPANamespaces: []string{"a"}}, nil, true, nil),
+)
@@ -0,0 +1,213 @@
+package export
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// IndexFile is the name of the index file, at the root of the export
+	IndexFile = "index.yaml"
+
+	// IndexAPIVersion and IndexKind identify the index file. They are not registered in
+	// the konfluxctl scheme, so the index is skipped when the export is loaded in offline mode.
+	IndexAPIVersion = "konfluxctl/v1"
+	IndexKind       = "ExportIndex"
+
+	// lastAppliedAnnotation holds a full copy of the object as applied by kubectl
+	lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+)
+
+// IndexEntry is one exported object
+type IndexEntry struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	// Path of the manifest, relative to the root of the export
+	Path string `json:"path"`
+}
+
+// Index describes what an export captured and when
+type Index struct {
+	APIVersion string      `json:"apiVersion"`
+	Kind       string      `json:"kind"`
+	CapturedAt metav1.Time `json:"capturedAt"`
+	// Cluster is the API server URL the objects were read from
+	Cluster string `json:"cluster"`
+	// Scope is the root of the exported graph, e.g. Application my-tenant/my-app
+	Scope   string       `json:"scope"`
+	Objects []IndexEntry `json:"objects"`
+}
+
+// IsArchive returns true when the output is a gzipped tarball, by extension
+func IsArchive(output string) bool {
+	return strings.HasSuffix(output, ".tar.gz") || strings.HasSuffix(output, ".tgz")
+}
+
+// Write writes one manifest per object, and the index, to the output directory or, see IsArchive, tarball.
+// Managed fields and the last applied configuration are stripped. Secrets are never written.
+func Write(output string, scheme *k8sruntime.Scheme, objs []client.Object, index Index) (*Index, error) {
+	index.APIVersion = IndexAPIVersion
+	index.Kind = IndexKind
+	index.Objects = []IndexEntry{}
+
+	files := map[string][]byte{}
+	for _, obj := range objs {
+		secret, err := isSecret(scheme, obj)
+		if err != nil {
+			return nil, err
+		}
+		if secret {
+			slog.Debug("export, secret skipped", "namespace", obj.GetNamespace(), "name", obj.GetName())
+			continue
+		}
+
+		entry, data, err := manifest(scheme, obj)
+		if err != nil {
+			return nil, err
+		}
+		files[entry.Path] = data
+		index.Objects = append(index.Objects, entry)
+	}
+
+	sort.Slice(index.Objects, func(i, j int) bool { return index.Objects[i].Path < index.Objects[j].Path })
+
+	indexData, err := yaml.Marshal(index)
+	if err != nil {
+		return nil, err
+	}
+	files[IndexFile] = indexData
+
+	if IsArchive(output) {
+		err = writeArchive(output, files, index.CapturedAt.Time)
+	} else {
+		err = writeDir(output, files)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &index, nil
+}
+
+// isSecret returns true for core Secrets, typed or unstructured
+func isSecret(scheme *k8sruntime.Scheme, obj client.Object) (bool, error) {
+	if _, ok := obj.(*corev1.Secret); ok {
+		return true, nil
+	}
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return false, err
+	}
+	return gvk.Group == corev1.GroupName && gvk.Kind == "Secret", nil
+}
+
+// manifest returns the YAML manifest of the object, with its apiVersion and kind
+func manifest(scheme *k8sruntime.Scheme, obj client.Object) (IndexEntry, []byte, error) {
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return IndexEntry{}, nil, err
+	}
+
+	obj = obj.DeepCopyObject().(client.Object)
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	obj.SetManagedFields(nil)
+	if annotations := obj.GetAnnotations(); annotations != nil {
+		delete(annotations, lastAppliedAnnotation)
+		obj.SetAnnotations(annotations)
+	}
+
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return IndexEntry{}, nil, err
+	}
+
+	namespace := obj.GetNamespace()
+	if namespace == "" {
+		namespace = "_cluster"
+	}
+
+	return IndexEntry{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		Path:       filepath.ToSlash(filepath.Join(namespace, strings.ToLower(gvk.Kind), obj.GetName()+".yaml")),
+	}, data, nil
+}
+
+// writeDir writes the files to the directory, which must be empty or not exist:
+// manifests left by a previous export would be loaded along with the new ones
+func writeDir(dir string, files map[string][]byte) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("output directory %s is not empty", dir)
+	}
+
+	for path, data := range files {
+		fullPath := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(fullPath, data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeArchive(output string, files map[string][]byte, modTime time.Time) (err error) {
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		header := &tar.Header{Name: path, Mode: 0o644, Size: int64(len(files[path])), ModTime: modTime, Typeflag: tar.TypeReg}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+		if _, err := tarWriter.Write(files[path]); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
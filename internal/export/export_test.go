@@ -0,0 +1,200 @@
+package export
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/eguzki/konfluxctl/internal/kube"
+)
+
+var _ = Describe("Write", func() {
+	var (
+		objs  []client.Object
+		index Index
+	)
+
+	BeforeEach(func() {
+		release := &konfluxapi.Release{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:          "release-a",
+				Namespace:     "my-tenant",
+				ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+				Annotations:   map[string]string{lastAppliedAnnotation: "{}", "keep": "me"},
+			},
+			Spec: konfluxapi.ReleaseSpec{ReleasePlan: "my-rp", Snapshot: "snapshot-a"},
+		}
+		objs = []client.Object{release}
+		index = Index{
+			CapturedAt: metav1.NewTime(time.Date(2025, time.May, 1, 10, 0, 0, 0, time.UTC)),
+			Cluster:    "https://api.example.com:6443",
+			Scope:      "Application my-tenant/my-application",
+		}
+	})
+
+	expectIndex := func(written *Index) {
+		Expect(written.Kind).To(Equal(IndexKind))
+		Expect(written.Objects).To(Equal([]IndexEntry{{
+			APIVersion: "appstudio.redhat.com/v1alpha1",
+			Kind:       "Release",
+			Namespace:  "my-tenant",
+			Name:       "release-a",
+			Path:       "my-tenant/release/release-a.yaml",
+		}}))
+	}
+
+	It("writes a directory loadable in offline mode", func() {
+		scheme, err := kube.NewScheme()
+		Expect(err).ToNot(HaveOccurred())
+		dir := GinkgoT().TempDir()
+
+		written, err := Write(dir, scheme, objs, index)
+		Expect(err).ToNot(HaveOccurred())
+		expectIndex(written)
+		Expect(filepath.Join(dir, IndexFile)).To(BeAnExistingFile())
+
+		loaded, err := kube.LoadManifests(scheme, []string{dir}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded).To(HaveLen(1))
+		release := loaded[0].(*konfluxapi.Release)
+		Expect(release.Spec.Snapshot).To(Equal("snapshot-a"))
+		Expect(release.ManagedFields).To(BeEmpty())
+		Expect(release.Annotations).To(Equal(map[string]string{"keep": "me"}))
+
+		// the objects are not modified
+		Expect(objs[0].GetManagedFields()).To(HaveLen(1))
+	})
+
+	It("refuses non empty directories", func() {
+		scheme, err := kube.NewScheme()
+		Expect(err).ToNot(HaveOccurred())
+		dir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "stale.yaml"), []byte("kind: Release\n"), 0o644)).To(Succeed())
+
+		_, err = Write(dir, scheme, objs, index)
+		Expect(err).To(MatchError(ContainSubstring("is not empty")))
+		Expect(filepath.Join(dir, IndexFile)).NotTo(BeAnExistingFile())
+	})
+
+	It("writes a tarball loadable in offline mode", func() {
+		scheme, err := kube.NewScheme()
+		Expect(err).ToNot(HaveOccurred())
+		output := filepath.Join(GinkgoT().TempDir(), "export.tar.gz")
+
+		written, err := Write(output, scheme, objs, index)
+		Expect(err).ToNot(HaveOccurred())
+		expectIndex(written)
+
+		file, err := os.Open(output)
+		Expect(err).ToNot(HaveOccurred())
+		defer file.Close()
+		gzipReader, err := gzip.NewReader(file)
+		Expect(err).ToNot(HaveOccurred())
+		tarReader := tar.NewReader(gzipReader)
+
+		names := []string{}
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				break
+			}
+			Expect(err).ToNot(HaveOccurred())
+			names = append(names, header.Name)
+		}
+		Expect(names).To(Equal([]string{IndexFile, "my-tenant/release/release-a.yaml"}))
+
+		loaded, err := kube.LoadManifests(scheme, nil, []string{output})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded).To(HaveLen(1))
+		release := loaded[0].(*konfluxapi.Release)
+		Expect(release.Name).To(Equal("release-a"))
+		Expect(release.Spec.Snapshot).To(Equal("snapshot-a"))
+	})
+
+	DescribeTable("never writes secrets",
+		func(output func() string) {
+			scheme, err := kube.NewScheme()
+			Expect(err).ToNot(HaveOccurred())
+
+			typed := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "my-tenant"},
+				Data:       map[string][]byte{"password": []byte("typed-s3cr3t")},
+				StringData: map[string]string{"token": "string-s3cr3t"},
+			}
+			untyped := &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"metadata":   map[string]any{"name": "other-token", "namespace": "my-tenant"},
+				"stringData": map[string]any{"token": "unstructured-s3cr3t"},
+			}}
+			out := output()
+
+			written, err := Write(out, scheme, append(objs, typed, untyped), index)
+			Expect(err).ToNot(HaveOccurred())
+			expectIndex(written)
+
+			contents := exportedFiles(out)
+			Expect(contents).To(HaveKey(IndexFile))
+			for path, data := range contents {
+				Expect(path).NotTo(ContainSubstring("secret"))
+				for _, value := range []string{"typed-s3cr3t", "string-s3cr3t", "unstructured-s3cr3t"} {
+					Expect(string(data)).NotTo(ContainSubstring(value), path)
+					Expect(string(data)).NotTo(ContainSubstring(base64.StdEncoding.EncodeToString([]byte(value))), path)
+				}
+			}
+		},
+		Entry("directory", func() string { return GinkgoT().TempDir() }),
+		Entry("tarball", func() string { return filepath.Join(GinkgoT().TempDir(), "export.tgz") }),
+	)
+})
+
+// exportedFiles returns the content of every file of the export directory or tarball, by path
+func exportedFiles(output string) map[string][]byte {
+	files := map[string][]byte{}
+
+	if !IsArchive(output) {
+		Expect(filepath.WalkDir(output, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(output, path)
+			files[filepath.ToSlash(rel)] = data
+			return err
+		})).To(Succeed())
+		return files
+	}
+
+	file, err := os.Open(output)
+	Expect(err).ToNot(HaveOccurred())
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	Expect(err).ToNot(HaveOccurred())
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return files
+		}
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(tarReader)
+		Expect(err).ToNot(HaveOccurred())
+		files[header.Name] = data
+	}
+}
@@ -0,0 +1,107 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// CachingClient memoizes reads for the lifetime of the client: every object
+// is fetched at most once by key and every list at most once by namespace, kind and selectors.
+// Writes are not cached and do not invalidate cached reads, so it is meant to be used
+// for one command invocation. It is safe for concurrent use.
+type CachingClient struct {
+	client.Client
+
+	entries sync.Map
+
+	gets      atomic.Int64
+	getCalls  atomic.Int64
+	lists     atomic.Int64
+	listCalls atomic.Int64
+}
+
+var _ client.Client = &CachingClient{}
+
+type cacheEntry struct {
+	once sync.Once
+	obj  k8sruntime.Object
+	err  error
+}
+
+func NewCachingClient(c client.Client) *CachingClient {
+	return &CachingClient{Client: c}
+}
+
+func (c *CachingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+
+	c.gets.Add(1)
+	cacheKey := fmt.Sprintf("get/%s/%s", gvk, key)
+	return c.load(cacheKey, obj, func() error {
+		c.getCalls.Add(1)
+		return c.Client.Get(ctx, key, obj, opts...)
+	})
+}
+
+func (c *CachingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	gvk, err := apiutil.GVKForObject(list, c.Scheme())
+	if err != nil {
+		return err
+	}
+
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+
+	c.lists.Add(1)
+	cacheKey := fmt.Sprintf("list/%s/%s/%v/%v/%d/%s",
+		gvk, listOpts.Namespace, listOpts.LabelSelector, listOpts.FieldSelector, listOpts.Limit, listOpts.Continue)
+	return c.load(cacheKey, list, func() error {
+		c.listCalls.Add(1)
+		return c.Client.List(ctx, list, opts...)
+	})
+}
+
+// load fills obj from the cache, calling fetch to fill obj on the first access
+func (c *CachingClient) load(cacheKey string, obj k8sruntime.Object, fetch func() error) error {
+	value, _ := c.entries.LoadOrStore(cacheKey, &cacheEntry{})
+	entry := value.(*cacheEntry)
+
+	fetched := false
+	entry.once.Do(func() {
+		fetched = true
+		entry.err = fetch()
+		if entry.err == nil {
+			entry.obj = obj.DeepCopyObject()
+		}
+	})
+
+	if fetched || entry.err != nil {
+		return entry.err
+	}
+
+	// copy, callers may modify the returned object
+	reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(entry.obj.DeepCopyObject()).Elem())
+	return nil
+}
+
+// LogStats logs the cache counters at debug level
+func (c *CachingClient) LogStats() {
+	gets, getCalls := c.gets.Load(), c.getCalls.Load()
+	lists, listCalls := c.lists.Load(), c.listCalls.Load()
+	slog.Debug("kube client cache",
+		"get requests", gets, "get api calls", getCalls,
+		"list requests", lists, "list api calls", listCalls,
+		"api calls saved", (gets-getCalls)+(lists-listCalls),
+	)
+}
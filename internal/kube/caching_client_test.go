@@ -0,0 +1,93 @@
+package kube
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("CachingClient", func() {
+	var (
+		ctx       context.Context
+		getCalls  atomic.Int64
+		listCalls atomic.Int64
+		cached    *CachingClient
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		getCalls.Store(0)
+		listCalls.Store(0)
+
+		scheme, err := NewScheme()
+		Expect(err).ToNot(HaveOccurred())
+		k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&konfluxapi.Release{ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "ns1"}},
+			&konfluxapi.Release{ObjectMeta: metav1.ObjectMeta{Name: "r2", Namespace: "ns1"}},
+			&konfluxapi.Release{ObjectMeta: metav1.ObjectMeta{Name: "r3", Namespace: "ns2"}},
+		).WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				getCalls.Add(1)
+				return c.Get(ctx, key, obj, opts...)
+			},
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				listCalls.Add(1)
+				return c.List(ctx, list, opts...)
+			},
+		}).Build()
+		cached = NewCachingClient(k8sClient)
+	})
+
+	It("fetches each object once", func() {
+		for range 3 {
+			release := &konfluxapi.Release{}
+			Expect(cached.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "r1"}, release)).To(Succeed())
+			Expect(release.Name).To(Equal("r1"))
+			// callers get a copy
+			release.Name = "modified"
+		}
+		Expect(getCalls.Load()).To(Equal(int64(1)))
+
+		release := &konfluxapi.Release{}
+		Expect(cached.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "r2"}, release)).To(Succeed())
+		Expect(getCalls.Load()).To(Equal(int64(2)))
+	})
+
+	It("memoizes not found errors", func() {
+		for range 2 {
+			err := cached.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "missing"}, &konfluxapi.Release{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		}
+		Expect(getCalls.Load()).To(Equal(int64(1)))
+	})
+
+	It("lists each namespace once", func() {
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Go(func() {
+				defer GinkgoRecover()
+				releaseList := &konfluxapi.ReleaseList{}
+				Expect(cached.List(ctx, releaseList, client.InNamespace("ns1"))).To(Succeed())
+				Expect(releaseList.Items).To(HaveLen(2))
+			})
+		}
+		wg.Wait()
+		Expect(listCalls.Load()).To(Equal(int64(1)))
+
+		releaseList := &konfluxapi.ReleaseList{}
+		Expect(cached.List(ctx, releaseList, client.InNamespace("ns2"))).To(Succeed())
+		Expect(releaseList.Items).To(HaveLen(1))
+		Expect(listCalls.Load()).To(Equal(int64(2)))
+
+		cached.LogStats()
+	})
+})
@@ -0,0 +1,82 @@
+package kube
+
+import (
+	"context"
+
+	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// NewScheme returns the scheme with every type konfluxctl reads
+func NewScheme() (*k8sruntime.Scheme, error) {
+	scheme := k8sruntime.NewScheme()
+	if err := konfluxapi.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := applicationapi.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return scheme, nil
+}
+
+// CurrentCluster returns the API server URL of the current kubeconfig context
+func CurrentCluster() (string, error) {
+	configuration, err := config.GetConfig()
+	if err != nil {
+		return "", err
+	}
+	return configuration.Host, nil
+}
+
+// CurrentNamespace returns the namespace of the current kubeconfig context, "default" when unset
+func CurrentNamespace() (string, error) {
+	loader := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{})
+	namespace, _, err := loader.Namespace()
+	return namespace, err
+}
+
+// Namespace returns the namespace flag value, or the namespace of the current kubeconfig context when unset
+func Namespace(flagNamespace string) (string, error) {
+	if flagNamespace != "" {
+		return flagNamespace, nil
+	}
+	return CurrentNamespace()
+}
+
+// NewClient returns a client for the cluster of the current kubeconfig context,
+// or the offline client of the context, see WithOfflineClient.
+// Requests are recorded when the context asks to, see WithRecording.
+func NewClient(ctx context.Context) (client.Client, error) {
+	return NewWatchClient(ctx)
+}
+
+// NewWatchClient is NewClient, able to watch objects
+func NewWatchClient(ctx context.Context) (client.WithWatch, error) {
+	if c := offlineClient(ctx); c != nil {
+		return recordingClient(ctx, c), nil
+	}
+
+	scheme, err := NewScheme()
+	if err != nil {
+		return nil, err
+	}
+
+	configuration, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := client.NewWithWatch(configuration, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+	return recordingClient(ctx, c), nil
+}
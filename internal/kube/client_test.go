@@ -0,0 +1,39 @@
+package kube
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const kubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: my-cluster
+  cluster:
+    server: https://api.cluster.example.com:6443
+contexts:
+- name: my-context
+  context:
+    cluster: my-cluster
+    namespace: my-tenant
+current-context: my-context
+`
+
+var _ = Describe("Namespace", func() {
+	BeforeEach(func() {
+		file := filepath.Join(GinkgoT().TempDir(), "kubeconfig")
+		Expect(os.WriteFile(file, []byte(kubeconfig), 0o600)).To(Succeed())
+		GinkgoT().Setenv("KUBECONFIG", file)
+	})
+
+	It("returns the flag value", func() {
+		Expect(Namespace("other-tenant")).To(Equal("other-tenant"))
+	})
+
+	It("defaults to the namespace of the current kubeconfig context", func() {
+		Expect(Namespace("")).To(Equal("my-tenant"))
+	})
+})
@@ -0,0 +1,246 @@
+package kube
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// manifestExtensions are the file extensions loaded from a directory
+var manifestExtensions = []string{".yaml", ".yml", ".json"}
+
+type offlineClientKey struct{}
+
+// WithOfflineClient returns a copy of the context where NewClient and NewWatchClient
+// return the client instead of a client for the cluster of the current kubeconfig context
+func WithOfflineClient(ctx context.Context, c client.WithWatch) context.Context {
+	return context.WithValue(ctx, offlineClientKey{}, c)
+}
+
+// IsOffline returns true when the context carries an offline client
+func IsOffline(ctx context.Context) bool {
+	return offlineClient(ctx) != nil
+}
+
+func offlineClient(ctx context.Context) client.WithWatch {
+	c, _ := ctx.Value(offlineClientKey{}).(client.WithWatch)
+	return c
+}
+
+// NewOfflineClient returns an in-memory client serving the objects loaded from the manifests,
+// see LoadManifests
+func NewOfflineClient(dirs, files []string) (client.WithWatch, error) {
+	scheme, err := NewScheme()
+	if err != nil {
+		return nil, err
+	}
+
+	objs, err := LoadManifests(scheme, dirs, files)
+	if err != nil {
+		return nil, err
+	}
+
+	// the same object may be in several exports, the last one wins. The fake client rejects duplicates.
+	unique := map[string]client.Object{}
+	keys := []string{}
+	for _, obj := range objs {
+		key := fmt.Sprintf("%T/%s/%s", obj, obj.GetNamespace(), obj.GetName())
+		if _, ok := unique[key]; !ok {
+			keys = append(keys, key)
+		}
+		unique[key] = obj
+	}
+
+	slog.Debug("offline mode", "objects", len(keys))
+
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, key := range keys {
+		builder = builder.WithObjects(unique[key])
+	}
+	return builder.Build(), nil
+}
+
+// LoadManifests reads the objects of the files, and of the YAML and JSON files of the directories, recursively.
+// Files may have several YAML documents, and documents may be a `kind: List`, like `kubectl get -o yaml` exports.
+// Files ending with .tar.gz or .tgz are gzipped tarballs, like `konfluxctl export` writes, their YAML and JSON
+// files are read. Objects of kinds not in the scheme are skipped. Managed fields are dropped.
+func LoadManifests(scheme *k8sruntime.Scheme, dirs, files []string) ([]client.Object, error) {
+	paths := append([]string{}, files...)
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() && isManifest(path) {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("reading manifests directory: %w", err)
+		}
+	}
+
+	objs := []client.Object{}
+	for _, path := range paths {
+		load := loadManifestFile
+		if isArchive(path) {
+			load = loadManifestArchive
+		}
+		fileObjs, err := load(scheme, path)
+		if err != nil {
+			return nil, fmt.Errorf("loading %s: %w", path, err)
+		}
+		objs = append(objs, fileObjs...)
+	}
+
+	return objs, nil
+}
+
+func isManifest(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, manifestExt := range manifestExtensions {
+		if ext == manifestExt {
+			return true
+		}
+	}
+	return false
+}
+
+// isArchive returns true when the file is a gzipped tarball, by extension
+func isArchive(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+func loadManifestFile(scheme *k8sruntime.Scheme, path string) ([]client.Object, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return decodeManifests(scheme, file)
+}
+
+func loadManifestArchive(scheme *k8sruntime.Scheme, path string) ([]client.Object, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+
+	objs := []client.Object{}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg || !isManifest(header.Name) {
+			continue
+		}
+
+		entryObjs, err := decodeManifests(scheme, tarReader)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", header.Name, err)
+		}
+		objs = append(objs, entryObjs...)
+	}
+}
+
+// decodeManifests reads the objects of the YAML documents, or JSON objects, of the reader
+func decodeManifests(scheme *k8sruntime.Scheme, r io.Reader) ([]client.Object, error) {
+	objs := []client.Object{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		doc := &unstructured.Unstructured{}
+		err := decoder.Decode(&doc.Object)
+		if errors.Is(err, io.EOF) {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		// empty documents, e.g. a leading ---
+		if len(doc.Object) == 0 {
+			continue
+		}
+
+		if !doc.IsList() {
+			obj, err := typedObject(scheme, doc)
+			if err != nil {
+				return nil, err
+			}
+			if obj != nil {
+				objs = append(objs, obj)
+			}
+			continue
+		}
+
+		err = doc.EachListItem(func(item k8sruntime.Object) error {
+			obj, err := typedObject(scheme, item.(*unstructured.Unstructured))
+			if err != nil {
+				return err
+			}
+			if obj != nil {
+				objs = append(objs, obj)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// typedObject converts the object to its scheme type. It returns nil for kinds not in the scheme.
+func typedObject(scheme *k8sruntime.Scheme, u *unstructured.Unstructured) (client.Object, error) {
+	gvk := u.GroupVersionKind()
+	if gvk.Kind == "" {
+		return nil, fmt.Errorf("object %q has no kind", u.GetName())
+	}
+
+	typed, err := scheme.New(gvk)
+	if k8sruntime.IsNotRegisteredError(err) {
+		slog.Debug("offline mode, kind skipped", "kind", gvk.String(), "name", u.GetName())
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := k8sruntime.DefaultUnstructuredConverter.FromUnstructured(u.Object, typed); err != nil {
+		return nil, fmt.Errorf("converting %s %s: %w", gvk.Kind, u.GetName(), err)
+	}
+
+	obj, ok := typed.(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an object", gvk.Kind)
+	}
+	// not needed offline, and the in-memory client validates them
+	obj.SetManagedFields(nil)
+	return obj, nil
+}
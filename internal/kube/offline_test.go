@@ -0,0 +1,123 @@
+package kube
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const releasesManifest = `---
+apiVersion: appstudio.redhat.com/v1alpha1
+kind: Release
+metadata:
+  name: release-a
+  namespace: my-tenant
+  resourceVersion: "12345"
+  managedFields:
+  - manager: kubectl
+spec:
+  releasePlan: my-rp
+  snapshot: snapshot-a
+status:
+  conditions:
+  - type: Released
+    status: "True"
+    reason: Succeeded
+    lastTransitionTime: "2025-05-01T10:00:00Z"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: skipped
+  namespace: my-tenant
+`
+
+const snapshotListManifest = `{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {
+      "apiVersion": "appstudio.redhat.com/v1alpha1",
+      "kind": "Snapshot",
+      "metadata": {"name": "snapshot-a", "namespace": "my-tenant"},
+      "spec": {"application": "my-application"}
+    },
+    {
+      "apiVersion": "appstudio.redhat.com/v1alpha1",
+      "kind": "Snapshot",
+      "metadata": {"name": "snapshot-b", "namespace": "my-tenant"},
+      "spec": {"application": "my-application"}
+    }
+  ]
+}`
+
+var _ = Describe("Offline client", func() {
+	var (
+		ctx context.Context
+		dir string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		dir = GinkgoT().TempDir()
+		Expect(os.MkdirAll(filepath.Join(dir, "snapshots"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "releases.yaml"), []byte(releasesManifest), 0o600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "snapshots", "list.json"), []byte(snapshotListManifest), 0o600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "README.md"), []byte("# not a manifest"), 0o600)).To(Succeed())
+	})
+
+	It("serves the objects of a directory", func() {
+		offline, err := NewOfflineClient([]string{dir}, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx = WithOfflineClient(ctx, offline)
+		Expect(IsOffline(ctx)).To(BeTrue())
+		k8sClient, err := NewClient(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		release := &konfluxapi.Release{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: "my-tenant", Name: "release-a"}, release)).To(Succeed())
+		Expect(release.Spec.Snapshot).To(Equal("snapshot-a"))
+		Expect(meta.IsStatusConditionTrue(release.Status.Conditions, "Released")).To(BeTrue())
+
+		snapshots := &applicationapi.SnapshotList{}
+		Expect(k8sClient.List(ctx, snapshots)).To(Succeed())
+		Expect(snapshots.Items).To(HaveLen(2))
+	})
+
+	It("accepts the same object in several files", func() {
+		offline, err := NewOfflineClient([]string{dir}, []string{filepath.Join(dir, "releases.yaml")})
+		Expect(err).ToNot(HaveOccurred())
+
+		releases := &konfluxapi.ReleaseList{}
+		Expect(offline.List(ctx, releases)).To(Succeed())
+		Expect(releases.Items).To(HaveLen(1))
+	})
+
+	It("serves the objects of a file", func() {
+		offline, err := NewOfflineClient(nil, []string{filepath.Join(dir, "snapshots", "list.json")})
+		Expect(err).ToNot(HaveOccurred())
+
+		releases := &konfluxapi.ReleaseList{}
+		Expect(offline.List(ctx, releases)).To(Succeed())
+		Expect(releases.Items).To(BeEmpty())
+	})
+
+	It("rejects invalid manifests", func() {
+		path := filepath.Join(dir, "invalid.yaml")
+		Expect(os.WriteFile(path, []byte("metadata:\n  name: no-kind\n"), 0o600)).To(Succeed())
+		_, err := NewOfflineClient(nil, []string{path})
+		Expect(err).To(MatchError(ContainSubstring(`object "no-kind" has no kind`)))
+	})
+
+	It("is not offline by default", func() {
+		Expect(IsOffline(ctx)).To(BeFalse())
+	})
+})
@@ -0,0 +1,245 @@
+package kube
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// Interaction is one recorded Get or List request with its response
+type Interaction struct {
+	// Request identifies the request: verb, kind, namespace, name and list options
+	Request string `json:"request"`
+	// Response is the returned object or list, unset on errors
+	Response json.RawMessage `json:"response,omitempty"`
+	// Status is the API error, unset on success
+	Status *metav1.Status `json:"status,omitempty"`
+	// Error is a non API error, e.g. a network error
+	Error string `json:"error,omitempty"`
+}
+
+func getRequest(c client.Client, key client.ObjectKey, obj client.Object) (string, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("get/%s/%s", gvk, key), nil
+}
+
+func listRequest(c client.Client, list client.ObjectList, opts []client.ListOption) (string, error) {
+	gvk, err := apiutil.GVKForObject(list, c.Scheme())
+	if err != nil {
+		return "", err
+	}
+
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+
+	return fmt.Sprintf("list/%s/%s/%v/%v/%d/%s",
+		gvk, listOpts.Namespace, listOpts.LabelSelector, listOpts.FieldSelector, listOpts.Limit, listOpts.Continue), nil
+}
+
+// RecordingClient writes every Get and List request, with its response, to a file, as JSON lines.
+// Interactions are written as they happen, so failing invocations are recorded too.
+// It is safe for concurrent use.
+type RecordingClient struct {
+	client.WithWatch
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+var _ client.WithWatch = &RecordingClient{}
+
+func NewRecordingClient(c client.WithWatch, out io.Writer) *RecordingClient {
+	return &RecordingClient{WithWatch: c, out: out}
+}
+
+func (c *RecordingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	request, err := getRequest(c, key, obj)
+	if err != nil {
+		return err
+	}
+
+	err = c.WithWatch.Get(ctx, key, obj, opts...)
+	c.record(request, obj, err)
+	return err
+}
+
+func (c *RecordingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	request, err := listRequest(c, list, opts)
+	if err != nil {
+		return err
+	}
+
+	err = c.WithWatch.List(ctx, list, opts...)
+	c.record(request, list, err)
+	return err
+}
+
+func (c *RecordingClient) record(request string, obj any, err error) {
+	interaction := Interaction{Request: request}
+
+	var apiStatus apierrors.APIStatus
+	switch {
+	case err == nil:
+		response, marshalErr := json.Marshal(obj)
+		if marshalErr != nil {
+			interaction.Error = marshalErr.Error()
+			break
+		}
+		interaction.Response = response
+	case errors.As(err, &apiStatus):
+		status := apiStatus.Status()
+		interaction.Status = &status
+	default:
+		interaction.Error = err.Error()
+	}
+
+	line, marshalErr := json.Marshal(interaction)
+	if marshalErr != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, _ = c.out.Write(append(line, '\n'))
+}
+
+// ReplayClient serves the interactions of a recording, see RecordingClient. It does not reach any cluster.
+// Identical requests are served in recorded order, the last response is served again once they are exhausted.
+// Writes go to an empty in-memory store and watches are not supported.
+// It is safe for concurrent use.
+type ReplayClient struct {
+	client.WithWatch
+
+	mu           sync.Mutex
+	interactions map[string][]Interaction
+}
+
+var _ client.WithWatch = &ReplayClient{}
+
+// NewReplayClient returns a client serving the interactions recorded in the file
+func NewReplayClient(path string) (*ReplayClient, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadReplay(file)
+}
+
+// ReadReplay returns a client serving the interactions recorded in the reader
+func ReadReplay(r io.Reader) (*ReplayClient, error) {
+	scheme, err := NewScheme()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &ReplayClient{
+		WithWatch:    fake.NewClientBuilder().WithScheme(scheme).Build(),
+		interactions: map[string][]Interaction{},
+	}
+
+	scanner := bufio.NewScanner(r)
+	// lists of a large namespace are long lines
+	scanner.Buffer(make([]byte, 0, 1024*1024), 64*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &interaction); err != nil {
+			return nil, fmt.Errorf("reading recording, line %d: %w", lineNumber, err)
+		}
+		c.interactions[interaction.Request] = append(c.interactions[interaction.Request], interaction)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading recording: %w", err)
+	}
+
+	return c, nil
+}
+
+func (c *ReplayClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	request, err := getRequest(c, key, obj)
+	if err != nil {
+		return err
+	}
+	return c.replay(request, obj)
+}
+
+func (c *ReplayClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	request, err := listRequest(c, list, opts)
+	if err != nil {
+		return err
+	}
+	return c.replay(request, list)
+}
+
+func (c *ReplayClient) Watch(ctx context.Context, list client.ObjectList, opts ...client.ListOption) (watch.Interface, error) {
+	return nil, errors.New("watch is not supported when replaying a recording")
+}
+
+func (c *ReplayClient) replay(request string, obj any) error {
+	c.mu.Lock()
+	recorded := c.interactions[request]
+	if len(recorded) == 0 {
+		c.mu.Unlock()
+		return fmt.Errorf("no recorded interaction for %s", request)
+	}
+	interaction := recorded[0]
+	if len(recorded) > 1 {
+		c.interactions[request] = recorded[1:]
+	}
+	c.mu.Unlock()
+
+	switch {
+	case interaction.Status != nil:
+		return &apierrors.StatusError{ErrStatus: *interaction.Status}
+	case interaction.Error != "":
+		return errors.New(interaction.Error)
+	}
+
+	return json.Unmarshal(interaction.Response, obj)
+}
+
+// IsReplaying returns true when the context serves a recording, see ReplayClient
+func IsReplaying(ctx context.Context) bool {
+	_, ok := offlineClient(ctx).(*ReplayClient)
+	return ok
+}
+
+type recordKey struct{}
+
+// WithRecording returns a copy of the context where NewClient and NewWatchClient
+// return clients recording their requests to out, see RecordingClient
+func WithRecording(ctx context.Context, out io.Writer) context.Context {
+	return context.WithValue(ctx, recordKey{}, out)
+}
+
+// IsRecording returns true when the context asks clients to record their requests
+func IsRecording(ctx context.Context) bool {
+	out, ok := ctx.Value(recordKey{}).(io.Writer)
+	return ok && out != nil
+}
+
+func recordingClient(ctx context.Context, c client.WithWatch) client.WithWatch {
+	if out, ok := ctx.Value(recordKey{}).(io.Writer); ok && out != nil {
+		return NewRecordingClient(c, out)
+	}
+	return c
+}
@@ -0,0 +1,116 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Record and replay", func() {
+	var (
+		ctx       context.Context
+		recording *bytes.Buffer
+		recorder  *RecordingClient
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme, err := NewScheme()
+		Expect(err).ToNot(HaveOccurred())
+		k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&konfluxapi.Release{
+				ObjectMeta: metav1.ObjectMeta{Name: "release-a", Namespace: "my-tenant"},
+				Spec:       konfluxapi.ReleaseSpec{Snapshot: "snapshot-a"},
+			},
+			&applicationapi.Snapshot{ObjectMeta: metav1.ObjectMeta{Name: "snapshot-a", Namespace: "my-tenant"}},
+		).Build()
+		recording = &bytes.Buffer{}
+		recorder = NewRecordingClient(k8sClient, recording)
+	})
+
+	It("replays recorded responses and errors", func() {
+		Expect(recorder.Get(ctx, client.ObjectKey{Namespace: "my-tenant", Name: "release-a"}, &konfluxapi.Release{})).To(Succeed())
+		err := recorder.Get(ctx, client.ObjectKey{Namespace: "my-tenant", Name: "missing"}, &konfluxapi.Release{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(recorder.List(ctx, &applicationapi.SnapshotList{}, client.InNamespace("my-tenant"))).To(Succeed())
+		Expect(strings.Count(recording.String(), "\n")).To(Equal(3))
+
+		replay, err := ReadReplay(recording)
+		Expect(err).ToNot(HaveOccurred())
+
+		release := &konfluxapi.Release{}
+		Expect(replay.Get(ctx, client.ObjectKey{Namespace: "my-tenant", Name: "release-a"}, release)).To(Succeed())
+		Expect(release.Spec.Snapshot).To(Equal("snapshot-a"))
+
+		err = replay.Get(ctx, client.ObjectKey{Namespace: "my-tenant", Name: "missing"}, &konfluxapi.Release{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		snapshots := &applicationapi.SnapshotList{}
+		Expect(replay.List(ctx, snapshots, client.InNamespace("my-tenant"))).To(Succeed())
+		Expect(snapshots.Items).To(HaveLen(1))
+	})
+
+	It("serves identical requests in recorded order, then repeats the last response", func() {
+		key := client.ObjectKey{Namespace: "my-tenant", Name: "release-a"}
+		release := &konfluxapi.Release{}
+		Expect(recorder.Get(ctx, key, release)).To(Succeed())
+		release.Spec.Snapshot = "snapshot-b"
+		Expect(recorder.Update(ctx, release)).To(Succeed())
+		Expect(recorder.Get(ctx, key, &konfluxapi.Release{})).To(Succeed())
+
+		replay, err := ReadReplay(recording)
+		Expect(err).ToNot(HaveOccurred())
+
+		snapshots := []string{}
+		for range 3 {
+			replayed := &konfluxapi.Release{}
+			Expect(replay.Get(ctx, key, replayed)).To(Succeed())
+			snapshots = append(snapshots, replayed.Spec.Snapshot)
+		}
+		Expect(snapshots).To(Equal([]string{"snapshot-a", "snapshot-b", "snapshot-b"}))
+	})
+
+	It("fails on requests not recorded", func() {
+		replay, err := ReadReplay(recording)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = replay.List(ctx, &konfluxapi.ReleaseList{})
+		Expect(err).To(MatchError(ContainSubstring("no recorded interaction for list/appstudio.redhat.com/v1alpha1, Kind=ReleaseList")))
+
+		_, err = replay.Watch(ctx, &konfluxapi.ReleaseList{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("tells replays from other offline clients", func() {
+		offline, err := NewOfflineClient(nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(IsReplaying(WithOfflineClient(ctx, offline))).To(BeFalse())
+
+		replay, err := ReadReplay(strings.NewReader(""))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(IsReplaying(WithOfflineClient(ctx, replay))).To(BeTrue())
+		Expect(IsReplaying(ctx)).To(BeFalse())
+	})
+
+	It("records through the client of the context", func() {
+		offline, err := NewOfflineClient(nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(IsRecording(ctx)).To(BeFalse())
+		ctx = WithRecording(WithOfflineClient(ctx, offline), recording)
+		Expect(IsRecording(ctx)).To(BeTrue())
+
+		k8sClient, err := NewClient(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(k8sClient.List(ctx, &konfluxapi.ReleaseList{})).To(Succeed())
+		Expect(recording.String()).To(HavePrefix(`{"request":"list/appstudio.redhat.com/v1alpha1, Kind=ReleaseList`))
+	})
+})
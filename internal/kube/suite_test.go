@@ -0,0 +1,19 @@
+package kube
+
+import (
+	"log/slog"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestKube(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Kube Suite")
+}
+
+var _ = BeforeSuite(func() {
+	By("Before suite")
+	slog.SetLogLoggerLevel(slog.LevelDebug)
+})
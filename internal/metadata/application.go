@@ -16,6 +16,10 @@ func (a *ApplicationElement) String() string {
 	return fmt.Sprintf("%s: %s", "Application", a.Name)
 }
 
+func (a *ApplicationElement) ID() string {
+	return objectID("Application", a.Namespace, a.Name)
+}
+
 func (a *ApplicationElement) Visit(path *Path) {
 	path.Application = &a.Name
 }
@@ -2,88 +2,88 @@ package metadata
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/ghodss/yaml"
 	"github.com/samber/lo"
+	"golang.org/x/sync/errgroup"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/eguzki/konfluxctl/internal/output"
 	"github.com/eguzki/konfluxctl/internal/utils"
 )
 
 type Path struct {
-	ReleasePlanAdmission *string  `json:"releasePlanAdmission"`
-	ReleasePlan          *string  `json:"releasePlan"`
-	Release              *string  `json:"release"`
-	Application          *string  `json:"application"`
-	SourceRevision       *string  `json:"sourceRevision"`
-	SourceURL            *string  `json:"sourceURL"`
-	Snapshot             *string  `json:"snapshot"`
-	ComponentName        *string  `json:"componentName"`
-	ImageTags            []string `json:"imageTags"`
-	Advisory             *string  `json:"advisory"`
-}
-
-func (p Path) ToJSON() (string, error) {
-	jsonBytes, err := json.Marshal(p)
-	if err != nil {
-		return "", err
-	}
-
-	return string(jsonBytes), nil
-
+	ReleasePlanAdmission  *string      `json:"releasePlanAdmission"`
+	ReleasePlan           *string      `json:"releasePlan"`
+	Release               *string      `json:"release"`
+	ReleaseCompletionTime *metav1.Time `json:"releaseCompletionTime"`
+	Application           *string      `json:"application"`
+	SourceRevision        *string      `json:"sourceRevision"`
+	SourceURL             *string      `json:"sourceURL"`
+	Snapshot              *string      `json:"snapshot"`
+	ComponentName         *string      `json:"componentName"`
+	ImageTags             []string     `json:"imageTags"`
+	Advisory              *string      `json:"advisory"`
+	// Platform of the image when it matched a multi-arch component by its image index
+	Platform *string `json:"platform,omitempty"`
+	// Missing lists the required fields not found. Only set on partial paths.
+	Missing []Field `json:"missing,omitempty"`
 }
 
-func (p Path) ToYAML() (string, error) {
-	jsonBytes, err := json.Marshal(p)
-	if err != nil {
-		return "", err
+func (p Path) String() string {
+	str := fmt.Sprintf(`ReleasePlanAdmission: %s
+Application: %s
+ReleasePlan: %s
+Release: %s
+Release Completion Time: %s
+Snapshot: %s
+Component: %s
+Source URL: %s
+Source Revision: %s
+Image Tags: %s
+Advisory: %s`,
+		lo.FromPtrOr(p.ReleasePlanAdmission, "<none>"),
+		lo.FromPtrOr(p.Application, "<none>"),
+		lo.FromPtrOr(p.ReleasePlan, "<none>"),
+		lo.FromPtrOr(p.Release, "<none>"),
+		completionTimeString(p.ReleaseCompletionTime),
+		lo.FromPtrOr(p.Snapshot, "<none>"),
+		lo.FromPtrOr(p.ComponentName, "<none>"),
+		lo.FromPtrOr(p.SourceURL, "<none>"),
+		lo.FromPtrOr(p.SourceRevision, "<none>"),
+		orNone(strings.Join(p.ImageTags, ",")),
+		lo.FromPtrOr(p.Advisory, "<none>"),
+	)
+
+	if p.Platform != nil {
+		str += fmt.Sprintf("\nPlatform: %s", *p.Platform)
 	}
-	yamlBytes, err := yaml.JSONToYAML(jsonBytes) // use `omitempty`'s from the json Marshal
-	if err != nil {
-		return "", err
+
+	if len(p.Missing) > 0 {
+		str += fmt.Sprintf("\nMissing: %s", strings.Join(lo.Map(p.Missing, func(f Field, _ int) string { return string(f) }), ","))
 	}
-	return string(yamlBytes), nil
+
+	return str
 }
 
-func (p Path) String() string {
-	return fmt.Sprintf(`ReleasePlanAddmision: %s
-Application: %s,
-ReleasePlan: %s,
-Release: %s,
-Snapshot: %s,
-Component: %s,
-Source URL: %s,
-Source Revision: %s,
-Image Tags: %s,
-Advisory: %s`,
-		lo.FromPtrOr(p.ReleasePlanAdmission, "<nil>"),
-		lo.FromPtrOr(p.Application, "<nil>"),
-		lo.FromPtrOr(p.ReleasePlan, "<nil>"),
-		lo.FromPtrOr(p.Release, "<nil>"),
-		lo.FromPtrOr(p.Snapshot, "<nil>"),
-		lo.FromPtrOr(p.ComponentName, "<nil>"),
-		lo.FromPtrOr(p.SourceURL, "<nil>"),
-		lo.FromPtrOr(p.SourceRevision, "<nil>"),
-		strings.Join(p.ImageTags, ","),
-		lo.FromPtrOr(p.Advisory, "<nil>"),
-	)
+func completionTimeString(t *metav1.Time) string {
+	if t == nil {
+		return "<none>"
+	}
+	return t.UTC().Format(time.RFC3339)
 }
 
+// IsComplete returns true when all the default required fields are set
 func (p Path) IsComplete() bool {
-	return p.ReleasePlanAdmission != nil &&
-		p.ReleasePlan != nil &&
-		p.Release != nil &&
-		p.Application != nil &&
-		p.SourceRevision != nil &&
-		p.SourceURL != nil &&
-		p.Snapshot != nil &&
-		p.ComponentName != nil &&
-		p.Advisory != nil &&
-		len(p.ImageTags) != 0
+	return len(p.MissingFields(DefaultRequiredFields)) == 0
 }
 
 func (p *Path) Clone() Path {
@@ -91,43 +91,275 @@ func (p *Path) Clone() Path {
 	return *p
 }
 
+// PathList is the set of complete paths found for one image.
+// The first path is the primary one, see SortPaths.
+type PathList []Path
+
+func (l PathList) String() string {
+	blocks := lo.Map(l, func(p Path, idx int) string {
+		header := fmt.Sprintf("Path %d/%d", idx+1, len(l))
+		if idx == 0 {
+			header += " (primary)"
+		}
+		return fmt.Sprintf("%s\n%s", header, p)
+	})
+	return strings.Join(blocks, "\n\n")
+}
+
+var pathColumns = []output.Column{
+	{Name: "RELEASE"}, {Name: "COMPLETED"}, {Name: "APPLICATION"}, {Name: "COMPONENT"}, {Name: "SNAPSHOT"},
+	{Name: "SOURCE REVISION"}, {Name: "ADVISORY"},
+	{Name: "RELEASE PLAN", Wide: true}, {Name: "RELEASE PLAN ADMISSION", Wide: true}, {Name: "SOURCE URL", Wide: true},
+	{Name: "IMAGE TAGS", Wide: true}, {Name: "PLATFORM", Wide: true}, {Name: "MISSING", Wide: true},
+}
+
+func (l PathList) Table() *output.Table {
+	return &output.Table{
+		Columns: pathColumns,
+		Rows: lo.Map(l, func(p Path, _ int) []string {
+			return []string{lo.FromPtrOr(p.Release, "-"), timeCell(p.ReleaseCompletionTime), lo.FromPtrOr(p.Application, "-"),
+				lo.FromPtrOr(p.ComponentName, "-"), lo.FromPtrOr(p.Snapshot, "-"), lo.FromPtrOr(p.SourceRevision, "-"),
+				lo.FromPtrOr(p.Advisory, "-"), lo.FromPtrOr(p.ReleasePlan, "-"), lo.FromPtrOr(p.ReleasePlanAdmission, "-"),
+				lo.FromPtrOr(p.SourceURL, "-"), cell(strings.Join(p.ImageTags, ",")), lo.FromPtrOr(p.Platform, "-"),
+				cell(strings.Join(lo.Map(p.Missing, func(f Field, _ int) string { return string(f) }), ","))}
+		}),
+	}
+}
+
+// Table is the one row table of the path, see PathList.Table
+func (p Path) Table() *output.Table {
+	return PathList{p}.Table()
+}
+
+// SortPaths orders paths deterministically. The most recently completed
+// release comes first, so the primary path is the latest shipment of the image.
+// Paths without completion time go last. Ties are broken by names.
+func SortPaths(paths []Path) {
+	sort.SliceStable(paths, func(i, j int) bool {
+		ti, tj := paths[i].ReleaseCompletionTime, paths[j].ReleaseCompletionTime
+		switch {
+		case ti != nil && tj == nil:
+			return true
+		case ti == nil && tj != nil:
+			return false
+		case ti != nil && tj != nil && !ti.Equal(tj):
+			return tj.Before(ti)
+		}
+		return pathSortKey(paths[i]) < pathSortKey(paths[j])
+	})
+}
+
+func pathSortKey(p Path) string {
+	return strings.Join([]string{
+		lo.FromPtr(p.Release),
+		lo.FromPtr(p.ReleasePlanAdmission),
+		lo.FromPtr(p.ReleasePlan),
+		lo.FromPtr(p.Snapshot),
+		lo.FromPtr(p.ComponentName),
+	}, "/")
+}
+
 type Node struct {
 	Element Element
 	Path    Path
+
+	// key identifies the node position in the search tree
+	key   []int
+	trace *TraceNode
 }
 
 type Element interface {
 	Visit(path *Path)
 	Children(ctx context.Context, k8sClient client.Client, imageURL *utils.ImageURL) ([]Element, error)
 	String() string
+	// ID identifies the object of the element, see objectID
+	ID() string
 }
 
-func DepthFirstSearch(ctx context.Context, k8sClient client.Client, imageURL *utils.ImageURL, elements []Element) ([]Path, error) {
-	completePaths := []Path{}
-	queue := []Node{}
+type searchOptions struct {
+	requiredFields []Field
+	partial        bool
+	concurrency    int
+}
 
-	for _, element := range elements {
-		queue = append(queue, Node{Element: element, Path: Path{}})
+// SearchOption configures DepthFirstSearch
+type SearchOption func(*searchOptions)
+
+// WithRequiredFields sets the fields a path needs to be complete.
+// By default, DefaultRequiredFields.
+func WithRequiredFields(fields []Field) SearchOption {
+	return func(o *searchOptions) {
+		o.requiredFields = fields
+	}
+}
+
+// WithPartialPaths makes the search return the deepest incomplete paths,
+// with the missing fields, when no complete path is found.
+func WithPartialPaths() SearchOption {
+	return func(o *searchOptions) {
+		o.partial = true
+	}
+}
+
+// WithConcurrency sets the maximum number of elements expanded concurrently.
+// By default, 1.
+func WithConcurrency(concurrency int) SearchOption {
+	return func(o *searchOptions) {
+		o.concurrency = concurrency
+	}
+}
+
+// ErrNoImageTags is returned when image tags are required and no ReleasePlanAdmission maps the repository with tags
+var ErrNoImageTags = errors.New("no ReleasePlanAdmission maps the image repository with tags")
+
+// DepthFirstSearch walks the lineage graph from the given elements and returns the complete paths.
+// Children are expanded concurrently, see WithConcurrency, but the result is the same as the
+// one of a serial depth first traversal.
+func DepthFirstSearch(ctx context.Context, k8sClient client.Client, imageURL *utils.ImageURL, elements []Element, opts ...SearchOption) ([]Path, error) {
+	options := &searchOptions{requiredFields: DefaultRequiredFields, concurrency: 1}
+	for _, opt := range opts {
+		opt(options)
 	}
 
-	for len(queue) > 0 {
-		current := queue[0]
-		slog.Debug("DepthFirstSearch ", "queue lenght", len(queue), "element", current.Element.String())
-		queue = queue[1:]
-		current.Element.Visit(&current.Path)
-		children, err := current.Element.Children(ctx, k8sClient, imageURL)
-		if err != nil {
-			return nil, err
+	// every path would miss the tags, the search can only come back empty
+	if !options.partial && slices.Contains(options.requiredFields, FieldImageTags) {
+		if untagged := untaggedReleasePlanAdmissions(elements); len(untagged) > 0 && len(untagged) == len(elements) {
+			return nil, fmt.Errorf("%w: %s", ErrNoImageTags, strings.Join(untagged, ","))
 		}
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	s := &search{
+		k8sClient: k8sClient,
+		imageURL:  imageURL,
+		options:   options,
+		group:     group,
+		sem:       make(chan struct{}, max(options.concurrency, 1)),
+	}
 
-		for _, child := range children {
-			// prepend
-			queue = append([]Node{{Element: child, Path: current.Path.Clone()}}, queue...)
+	rootTrace := currentTraceNode(ctx)
+	for idx, element := range elements {
+		node := Node{
+			Element: element,
+			Path:    Path{},
+			key:     []int{serialOrder(idx, len(elements))},
+			trace:   rootTrace.addChild(element),
 		}
+		group.Go(func() error { return s.expand(groupCtx, node) })
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	if len(s.completePaths) == 0 && options.partial {
+		return deepestPaths(s.partialPaths), nil
+	}
 
-		if current.Path.IsComplete() {
-			completePaths = append(completePaths, current.Path)
+	return sortedPaths(s.completePaths), nil
+}
+
+// untaggedReleasePlanAdmissions returns the names of the ReleasePlanAdmissions mapping the image repository with no tags
+func untaggedReleasePlanAdmissions(elements []Element) []string {
+	untagged := []string{}
+	for _, element := range elements {
+		if rpa, ok := element.(*ReleasePlanAdmissionElement); ok && len(rpa.tags) == 0 {
+			untagged = append(untagged, rpa.rawRPA.Namespace+"/"+rpa.rawRPA.Name)
 		}
 	}
-	return completePaths, nil
+	return untagged
+}
+
+// serialOrder returns the position in which the serial traversal visits the idx-th sibling.
+// The traversal was implemented with a stack, so the last sibling is visited first.
+func serialOrder(idx, siblings int) int {
+	return siblings - 1 - idx
+}
+
+type keyedPath struct {
+	key  []int
+	path Path
+}
+
+func sortedPaths(keyedPaths []keyedPath) []Path {
+	// the lexicographic order of the keys is the visit order of the serial traversal
+	slices.SortFunc(keyedPaths, func(a, b keyedPath) int {
+		return slices.Compare(a.key, b.key)
+	})
+
+	paths := lo.Map(keyedPaths, func(k keyedPath, _ int) Path { return k.path })
+	SortPaths(paths)
+
+	return paths
+}
+
+func deepestPaths(keyedPaths []keyedPath) []Path {
+	depth := lo.Max(lo.Map(keyedPaths, func(k keyedPath, _ int) int { return len(k.key) }))
+	return sortedPaths(lo.Filter(keyedPaths, func(k keyedPath, _ int) bool {
+		return len(k.key) == depth
+	}))
+}
+
+type search struct {
+	k8sClient client.Client
+	imageURL  *utils.ImageURL
+	options   *searchOptions
+	group     *errgroup.Group
+	// sem bounds the concurrent Children calls
+	sem chan struct{}
+
+	mu            sync.Mutex
+	completePaths []keyedPath
+	partialPaths  []keyedPath
+}
+
+func (s *search) expand(ctx context.Context, current Node) error {
+	slog.Debug("DepthFirstSearch ", "depth", len(current.key), "element", current.Element.String())
+	current.Element.Visit(&current.Path)
+
+	// select picks a ready case at random: a free slot would win over the cancellation half of the time
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case s.sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	children, err := current.Element.Children(withTraceNode(ctx, current.trace), s.k8sClient, s.imageURL)
+	<-s.sem
+	if err != nil {
+		return err
+	}
+
+	// paths are recorded at leaves only: with loose required fields, inner nodes are complete
+	// too and the same lineage would be returned once per node
+	if len(children) == 0 {
+		missing := current.Path.MissingFields(s.options.requiredFields)
+		if len(missing) == 0 {
+			s.record(&s.completePaths, current.key, current.Path)
+		} else {
+			current.Path.Missing = missing
+			s.record(&s.partialPaths, current.key, current.Path)
+		}
+		current.trace.leaf(current.Path, len(missing) == 0)
+	}
+
+	for idx, child := range children {
+		childNode := Node{
+			Element: child,
+			Path:    current.Path.Clone(),
+			key:     append(slices.Clone(current.key), serialOrder(idx, len(children))),
+			trace:   current.trace.addChild(child),
+		}
+		s.group.Go(func() error { return s.expand(ctx, childNode) })
+	}
+
+	return nil
+}
+
+func (s *search) record(paths *[]keyedPath, key []int, path Path) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	*paths = append(*paths, keyedPath{key: key, path: path})
 }
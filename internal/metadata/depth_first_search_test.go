@@ -0,0 +1,311 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/eguzki/konfluxctl/internal/kube"
+	"github.com/eguzki/konfluxctl/internal/utils"
+)
+
+var _ = Describe("DepthFirstSearch", func() {
+	var (
+		ctx      context.Context
+		imageURL *utils.ImageURL
+		t0       time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		t0 = time.Date(2025, time.May, 1, 10, 0, 0, 0, time.UTC)
+		var err error
+		imageURL, err = utils.ParseImageURL(testRepository + "@" + testDigest)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("returns every complete path, most recent release first", func() {
+		k8sClient := testClient(
+			testRPA("my-rpa", "my-rp", "my-rp-unmatched"),
+			testReleasePlan("my-rp", true),
+			testReleasePlan("my-rp-unmatched", false),
+			testRelease("release-ga", "my-rp", "snapshot-a", true, t0),
+			testRelease("release-z", "my-rp", "snapshot-b", true, t0.Add(time.Hour)),
+			testRelease("release-failed", "my-rp", "snapshot-a", false, t0.Add(2*time.Hour)),
+			testRelease("release-other", "my-rp", "snapshot-other", true, t0.Add(3*time.Hour)),
+			testSnapshot("snapshot-a", testDigest),
+			testSnapshot("snapshot-b", testDigest),
+			testSnapshot("snapshot-other", testOtherDigest),
+			testApplication(),
+		)
+
+		rpaList, err := ReleasePlanAdmissionList(ctx, k8sClient, imageURL.FamiliarName(), []string{testManagedNamespace})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rpaList).To(HaveLen(1))
+
+		paths, err := DepthFirstSearch(ctx, k8sClient, imageURL, rpaList)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lo.Map(paths, func(p Path, _ int) string { return *p.Release })).To(
+			Equal([]string{"release-z", "release-ga"}))
+
+		primary := paths[0]
+		Expect(primary.IsComplete()).To(BeTrue())
+		Expect(*primary.ReleasePlanAdmission).To(Equal("my-rpa"))
+		Expect(*primary.ReleasePlan).To(Equal("my-rp"))
+		Expect(*primary.Snapshot).To(Equal("snapshot-b"))
+		Expect(*primary.Application).To(Equal("my-application"))
+		Expect(*primary.SourceRevision).To(Equal("abc123"))
+		Expect(primary.ImageTags).To(Equal([]string{"latest", "1.0"}))
+		Expect(*primary.Advisory).To(Equal("https://access.redhat.com/errata/release-z"))
+	})
+
+	It("returns each lineage once with loose required fields", func() {
+		k8sClient := testClient(
+			testRPA("my-rpa", "my-rp"),
+			testReleasePlan("my-rp", true),
+			testRelease("release-ga", "my-rp", "snapshot-a", true, t0),
+			testRelease("release-z", "my-rp", "snapshot-b", true, t0.Add(time.Hour)),
+			testSnapshot("snapshot-a", testDigest),
+			testSnapshot("snapshot-b", testDigest),
+			testApplication(),
+		)
+
+		rpaList, err := ReleasePlanAdmissionList(ctx, k8sClient, imageURL.FamiliarName(), []string{testManagedNamespace})
+		Expect(err).ToNot(HaveOccurred())
+
+		paths, err := DepthFirstSearch(ctx, k8sClient, imageURL, rpaList, WithRequiredFields([]Field{FieldRelease}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lo.Map(paths, func(p Path, _ int) string { return *p.Release })).To(
+			Equal([]string{"release-z", "release-ga"}))
+		Expect(*paths[0].Application).To(Equal("my-application"))
+	})
+
+	It("fails when image tags are required and the repository is mapped without tags", func() {
+		rpa := testRPA("my-rpa", "my-rp")
+		rpa.Spec.Data = rawJSON(ReleasePlanAdmissionData{Mappping: ReleasePlanAdmissionDataMapping{
+			Components: []ReleasePlanAdmissionDataComponent{{
+				Name:         "my-component",
+				Repositories: []Repository{{Url: testRepository}},
+			}},
+		}})
+		k8sClient := testClient(
+			rpa,
+			testReleasePlan("my-rp", true),
+			testRelease("release-ga", "my-rp", "snapshot-a", true, t0),
+			testSnapshot("snapshot-a", testDigest),
+			testApplication(),
+		)
+
+		rpaList, err := ReleasePlanAdmissionList(ctx, k8sClient, imageURL.FamiliarName(), []string{testManagedNamespace})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = DepthFirstSearch(ctx, k8sClient, imageURL, rpaList)
+		Expect(err).To(MatchError(ErrNoImageTags))
+		Expect(err).To(MatchError(ContainSubstring(testManagedNamespace + "/my-rpa")))
+
+		paths, err := DepthFirstSearch(ctx, k8sClient, imageURL, rpaList, WithPartialPaths())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(paths).To(ConsistOf(HaveField("ImageTags", BeEmpty())))
+
+		paths, err = DepthFirstSearch(ctx, k8sClient, imageURL, rpaList, WithRequiredFields(lo.Without(DefaultRequiredFields, FieldImageTags)))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(paths).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("SortPaths", func() {
+	It("orders by completion time, newest first, then by name", func() {
+		t0 := time.Date(2025, time.May, 1, 10, 0, 0, 0, time.UTC)
+		paths := []Path{
+			{Release: ptr.To("no-time")},
+			{Release: ptr.To("b"), ReleaseCompletionTime: &metav1.Time{Time: t0}},
+			{Release: ptr.To("newest"), ReleaseCompletionTime: &metav1.Time{Time: t0.Add(time.Minute)}},
+			{Release: ptr.To("a"), ReleaseCompletionTime: &metav1.Time{Time: t0}},
+		}
+		SortPaths(paths)
+		Expect(lo.Map(paths, func(p Path, _ int) string { return *p.Release })).To(
+			Equal([]string{"newest", "a", "b", "no-time"}))
+	})
+})
+
+var _ = Describe("DepthFirstSearch partial paths", func() {
+	var (
+		ctx       context.Context
+		imageURL  *utils.ImageURL
+		k8sClient client.Client
+		rpaList   []Element
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		imageURL, err = utils.ParseImageURL(testRepository + "@" + testDigest)
+		Expect(err).ToNot(HaveOccurred())
+
+		snapshot := testSnapshot("snapshot-a", testDigest)
+		snapshot.Spec.Components[0].Source.GitSource = nil
+		release := testRelease("release-ga", "my-rp", "snapshot-a", true, time.Now())
+		release.Status.Artifacts = nil
+		k8sClient = testClient(
+			testRPA("my-rpa", "my-rp"),
+			testReleasePlan("my-rp", true),
+			release,
+			testRelease("release-other", "my-rp", "snapshot-other", true, time.Now()),
+			snapshot,
+			testSnapshot("snapshot-other", testOtherDigest),
+			testApplication(),
+		)
+
+		rpaList, err = ReleasePlanAdmissionList(ctx, k8sClient, imageURL.FamiliarName(), nil)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("returns nothing by default when the source is unknown", func() {
+		paths, err := DepthFirstSearch(ctx, k8sClient, imageURL, rpaList)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(paths).To(BeEmpty())
+	})
+
+	It("returns the deepest incomplete paths with the missing fields", func() {
+		paths, err := DepthFirstSearch(ctx, k8sClient, imageURL, rpaList, WithPartialPaths())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(paths).To(HaveLen(1))
+		Expect(*paths[0].Release).To(Equal("release-ga"))
+		Expect(*paths[0].Application).To(Equal("my-application"))
+		Expect(paths[0].Missing).To(Equal([]Field{FieldSourceRevision, FieldSourceURL}))
+		Expect(paths[0].String()).To(ContainSubstring("Missing: sourceRevision,sourceURL"))
+		Expect(paths[0].String()).To(ContainSubstring("Source URL: <none>\n"))
+		Expect(paths[0].String()).ToNot(ContainSubstring(",\n"))
+	})
+
+	It("uses the configured required fields", func() {
+		required, err := ParseFields([]string{"release", "snapshot", "application"})
+		Expect(err).ToNot(HaveOccurred())
+		paths, err := DepthFirstSearch(ctx, k8sClient, imageURL, rpaList, WithRequiredFields(required))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(paths).To(HaveLen(1))
+		Expect(paths[0].Missing).To(BeEmpty())
+		Expect(paths[0].Advisory).To(BeNil())
+	})
+
+	It("rejects unknown fields", func() {
+		_, err := ParseFields([]string{"release", "foo"})
+		Expect(err).To(MatchError(ContainSubstring(`unknown field "foo"`)))
+	})
+})
+
+var _ = Describe("DepthFirstSearch concurrency", func() {
+	var (
+		imageURL  *utils.ImageURL
+		objs      []client.Object
+		k8sClient client.Client
+	)
+
+	BeforeEach(func() {
+		var err error
+		imageURL, err = utils.ParseImageURL(testRepository + "@" + testDigest)
+		Expect(err).ToNot(HaveOccurred())
+
+		t0 := time.Date(2025, time.May, 1, 10, 0, 0, 0, time.UTC)
+		objs = []client.Object{testApplication(), testSnapshot("snapshot-a", testDigest), testSnapshot("snapshot-other", testOtherDigest)}
+		for i := range 5 {
+			rp := fmt.Sprintf("rp-%d", i)
+			objs = append(objs, testRPA(fmt.Sprintf("rpa-%d", i), rp), testReleasePlan(rp, true))
+			for j := range 4 {
+				snapshot := "snapshot-a"
+				if j%2 == 1 {
+					snapshot = "snapshot-other"
+				}
+				// same completion time, the order falls back to names
+				objs = append(objs, testRelease(fmt.Sprintf("release-%d-%d", i, j), rp, snapshot, true, t0))
+			}
+		}
+		k8sClient = testClient(objs...)
+	})
+
+	It("returns the same paths as the serial search", func() {
+		ctx := context.Background()
+		rpaList, err := ReleasePlanAdmissionList(ctx, k8sClient, imageURL.FamiliarName(), nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		serial, err := DepthFirstSearch(ctx, k8sClient, imageURL, rpaList)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(serial).To(HaveLen(10))
+
+		for range 5 {
+			concurrent, err := DepthFirstSearch(ctx, k8sClient, imageURL, rpaList, WithConcurrency(8))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(concurrent).To(Equal(serial))
+		}
+	})
+
+	It("stops when the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		rpaList, err := ReleasePlanAdmissionList(ctx, k8sClient, imageURL.FamiliarName(), nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		var calls atomic.Int32
+		countingClient := fake.NewClientBuilder().WithScheme(testScheme()).WithObjects(objs...).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					calls.Add(1)
+					return c.Get(ctx, key, obj, opts...)
+				},
+				List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+					calls.Add(1)
+					return c.List(ctx, list, opts...)
+				},
+			}).Build()
+
+		cancel()
+		for range 20 {
+			_, err = DepthFirstSearch(ctx, countingClient, imageURL, rpaList, WithConcurrency(4))
+			Expect(err).To(MatchError(context.Canceled))
+		}
+		Expect(calls.Load()).To(BeZero())
+	})
+})
+
+var _ = Describe("DepthFirstSearch replay", func() {
+	It("returns the same paths from a recording", func() {
+		ctx := context.Background()
+		imageURL, err := utils.ParseImageURL(testRepository + "@" + testDigest)
+		Expect(err).ToNot(HaveOccurred())
+
+		t0 := time.Date(2025, time.May, 1, 10, 0, 0, 0, time.UTC)
+		k8sClient := fake.NewClientBuilder().WithScheme(testScheme()).WithObjects(
+			testRPA("my-rpa", "my-rp"),
+			testReleasePlan("my-rp", true),
+			testRelease("release-ga", "my-rp", "snapshot-a", true, t0),
+			testSnapshot("snapshot-a", testDigest),
+			testApplication(),
+		).Build()
+
+		search := func(k8sClient client.Client) []Path {
+			rpaList, err := ReleasePlanAdmissionList(ctx, k8sClient, imageURL.FamiliarName(), nil)
+			Expect(err).ToNot(HaveOccurred())
+			paths, err := DepthFirstSearch(ctx, k8sClient, imageURL, rpaList, WithConcurrency(4))
+			Expect(err).ToNot(HaveOccurred())
+			return paths
+		}
+
+		recording := &bytes.Buffer{}
+		recorded := search(kube.NewRecordingClient(k8sClient, recording))
+		Expect(recorded).To(HaveLen(1))
+
+		replay, err := kube.ReadReplay(recording)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(search(replay)).To(Equal(recorded))
+	})
+})
@@ -0,0 +1,186 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Trace records the lineage search: every visited element, the candidates
+// filtered out by each Children implementation and the partial path at dead ends.
+// It is attached to the context with WithTrace and it is safe for concurrent use.
+type Trace struct {
+	mu   sync.Mutex
+	Root *TraceNode
+}
+
+// TraceNode is one visited element of the search tree
+type TraceNode struct {
+	Element  string        `json:"element"`
+	Notes    []string      `json:"notes,omitempty"`
+	Filtered []TraceFilter `json:"filtered,omitempty"`
+	// Path is set at the leaves of the search tree
+	Path     *Path        `json:"path,omitempty"`
+	Complete bool         `json:"complete"`
+	Children []*TraceNode `json:"children,omitempty"`
+
+	// id identifies the object of the element, labels are for display only
+	id    string
+	trace *Trace
+}
+
+// TraceFilter is a candidate discarded while expanding an element
+type TraceFilter struct {
+	Candidate string `json:"candidate"`
+	Reason    string `json:"reason"`
+
+	// id identifies the object of the candidate, see objectID
+	id string
+}
+
+// objectID identifies objects across the trace: names are unique by kind and namespace only
+func objectID(kind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+func NewTrace(root string) *Trace {
+	t := &Trace{}
+	t.Root = &TraceNode{Element: root, id: root, trace: t}
+	return t
+}
+
+// MarshalJSON marshals the search tree, from the root
+func (t *Trace) MarshalJSON() ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return json.Marshal(t.Root)
+}
+
+// String renders the trace as a human readable diagnosis tree
+func (t *Trace) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var b strings.Builder
+	b.WriteString(t.Root.Element)
+	b.WriteString("\n")
+	t.Root.render(&b, "")
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (n *TraceNode) render(b *strings.Builder, prefix string) {
+	lines := []func(string, string){}
+
+	for _, note := range n.Notes {
+		lines = append(lines, func(branch, _ string) {
+			fmt.Fprintf(b, "%s%sℹ️  %s\n", prefix, branch, note)
+		})
+	}
+
+	for _, filter := range n.Filtered {
+		lines = append(lines, func(branch, _ string) {
+			fmt.Fprintf(b, "%s%s❌ %s: %s\n", prefix, branch, filter.Candidate, filter.Reason)
+		})
+	}
+
+	for _, child := range n.Children {
+		lines = append(lines, func(branch, indent string) {
+			marker := ""
+			if child.Complete {
+				marker = "✅ "
+			}
+			fmt.Fprintf(b, "%s%s%s%s\n", prefix, branch, marker, child.Element)
+			child.render(b, prefix+indent)
+		})
+	}
+
+	if n.Path != nil && !n.Complete && n != n.trace.Root {
+		lines = append(lines, func(branch, indent string) {
+			fmt.Fprintf(b, "%s%s🛑 dead end, partial path:\n", prefix, branch)
+			for _, line := range strings.Split(n.Path.String(), "\n") {
+				fmt.Fprintf(b, "%s%s   %s\n", prefix, indent, line)
+			}
+		})
+	}
+
+	for idx, line := range lines {
+		if idx == len(lines)-1 {
+			line("└── ", "    ")
+		} else {
+			line("├── ", "│   ")
+		}
+	}
+}
+
+func (n *TraceNode) addChild(element Element) *TraceNode {
+	if n == nil {
+		return nil
+	}
+	n.trace.mu.Lock()
+	defer n.trace.mu.Unlock()
+	child := &TraceNode{Element: element.String(), id: element.ID(), trace: n.trace}
+	n.Children = append(n.Children, child)
+	return child
+}
+
+func (n *TraceNode) leaf(path Path, complete bool) {
+	if n == nil {
+		return
+	}
+	n.trace.mu.Lock()
+	defer n.trace.mu.Unlock()
+	n.Path = &path
+	n.Complete = complete
+}
+
+type traceKey struct{}
+
+type traceNodeKey struct{}
+
+// WithTrace returns a copy of the context recording the search on the trace
+func WithTrace(ctx context.Context, trace *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+func withTraceNode(ctx context.Context, node *TraceNode) context.Context {
+	if node == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, traceNodeKey{}, node)
+}
+
+// currentTraceNode returns the node being expanded, the root when the search
+// has not started yet, or nil when the search is not traced
+func currentTraceNode(ctx context.Context) *TraceNode {
+	if node, ok := ctx.Value(traceNodeKey{}).(*TraceNode); ok {
+		return node
+	}
+	if trace, ok := ctx.Value(traceKey{}).(*Trace); ok && trace != nil {
+		return trace.Root
+	}
+	return nil
+}
+
+// traceFilter records a candidate discarded while expanding the current element.
+// The id identifies the candidate object, see objectID.
+func traceFilter(ctx context.Context, id, candidate, reason string) {
+	node := currentTraceNode(ctx)
+	if node == nil {
+		return
+	}
+	node.trace.mu.Lock()
+	defer node.trace.mu.Unlock()
+	node.Filtered = append(node.Filtered, TraceFilter{Candidate: candidate, Reason: reason, id: id})
+}
+
+// traceNote records some information about the current element expansion
+func traceNote(ctx context.Context, format string, args ...any) {
+	node := currentTraceNode(ctx)
+	if node == nil {
+		return
+	}
+	node.trace.mu.Lock()
+	defer node.trace.mu.Unlock()
+	node.Notes = append(node.Notes, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,86 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/eguzki/konfluxctl/internal/utils"
+)
+
+var _ = Describe("Trace", func() {
+	It("records why the lineage search broke", func() {
+		t0 := time.Date(2025, time.May, 1, 10, 0, 0, 0, time.UTC)
+		k8sClient := testClient(
+			testRPA("my-rpa", "my-rp", "my-rp-unmatched"),
+			testReleasePlan("my-rp", true),
+			testReleasePlan("my-rp-unmatched", false),
+			testRelease("release-failed", "my-rp", "snapshot-a", false, t0),
+			testRelease("release-other", "my-rp", "snapshot-other", true, t0),
+			testSnapshot("snapshot-a", testDigest),
+			testSnapshot("snapshot-other", testOtherDigest),
+			testApplication(),
+		)
+
+		imageURL, err := utils.ParseImageURL(testRepository + "@" + testDigest)
+		Expect(err).ToNot(HaveOccurred())
+
+		trace := NewTrace("Image: " + testRepository)
+		ctx := WithTrace(context.Background(), trace)
+
+		rpaList, err := ReleasePlanAdmissionList(ctx, k8sClient, imageURL.FamiliarName(), nil)
+		Expect(err).ToNot(HaveOccurred())
+		paths, err := DepthFirstSearch(ctx, k8sClient, imageURL, rpaList)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(paths).To(BeEmpty())
+
+		Expect(trace.Root.Children).To(HaveLen(1))
+		rpaNode := trace.Root.Children[0]
+		Expect(rpaNode.Element).To(Equal("ReleasePlanAdmission: my-rpa"))
+		Expect(rpaNode.Filtered).To(ConsistOf(TraceFilter{
+			Candidate: "ReleasePlan my-tenant/my-rp-unmatched",
+			Reason:    "condition Matched is False (reason: Matched)",
+			id:        objectID("ReleasePlan", testTenantNamespace, "my-rp-unmatched"),
+		}))
+
+		Expect(rpaNode.Children).To(HaveLen(1))
+		rpNode := rpaNode.Children[0]
+		Expect(rpNode.Filtered).To(ConsistOf(TraceFilter{
+			Candidate: "Release release-failed",
+			Reason:    "condition Released is False (reason: Failed)",
+			id:        objectID("Release", testTenantNamespace, "release-failed"),
+		}))
+
+		Expect(rpNode.Children).To(HaveLen(1))
+		releaseNode := rpNode.Children[0]
+		Expect(releaseNode.Element).To(Equal("Release: release-other"))
+		Expect(releaseNode.Filtered).To(HaveLen(1))
+		Expect(releaseNode.Filtered[0].Candidate).To(Equal("Snapshot my-tenant/snapshot-other"))
+		Expect(releaseNode.Complete).To(BeFalse())
+		Expect(releaseNode.Path).ToNot(BeNil())
+		Expect(*releaseNode.Path.Release).To(Equal("release-other"))
+
+		Expect(trace.String()).To(ContainSubstring("dead end"))
+
+		jsonBytes, err := json.Marshal(trace)
+		Expect(err).ToNot(HaveOccurred())
+		var decoded TraceNode
+		Expect(json.Unmarshal(jsonBytes, &decoded)).To(Succeed())
+		Expect(decoded.Children).To(HaveLen(1))
+	})
+
+	It("marshals the search tree from the root", func() {
+		trace := NewTrace("Image: " + testRepository)
+		trace.Root.addChild(&ApplicationElement{ObjectMeta: metav1.ObjectMeta{Namespace: testTenantNamespace, Name: "my-application"}})
+
+		// the printer marshals the trace itself, not its root
+		jsonBytes, err := json.Marshal(trace)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jsonBytes).To(MatchJSON(`{"element":"Image: quay.io/org/my-app","complete":false,
+			"children":[{"element":"Application: my-application","complete":false}]}`))
+	})
+})
@@ -0,0 +1,192 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	"github.com/samber/lo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// releaseGraph collects the objects of the lineage graph, each object once
+type releaseGraph struct {
+	k8sClient client.Client
+	objects   map[string]client.Object
+	// releases of each namespace, listed once
+	releases map[string][]konfluxapi.Release
+}
+
+func newReleaseGraph(k8sClient client.Client) *releaseGraph {
+	return &releaseGraph{
+		k8sClient: k8sClient,
+		objects:   map[string]client.Object{},
+		releases:  map[string][]konfluxapi.Release{},
+	}
+}
+
+func (g *releaseGraph) add(obj client.Object) bool {
+	key := fmt.Sprintf("%T/%s/%s", obj, obj.GetNamespace(), obj.GetName())
+	if _, ok := g.objects[key]; ok {
+		return false
+	}
+	g.objects[key] = obj
+	return true
+}
+
+// get fetches and adds the object. Missing objects are skipped, dangling references are common.
+func (g *releaseGraph) get(ctx context.Context, namespace, name string, obj client.Object) error {
+	err := g.k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, obj)
+	if apierrors.IsNotFound(err) {
+		traceNote(ctx, "%T %s/%s not found", obj, namespace, name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	g.add(obj)
+	return nil
+}
+
+// sorted returns the objects sorted by type, namespace and name
+func (g *releaseGraph) sorted() []client.Object {
+	keys := lo.Keys(g.objects)
+	sort.Strings(keys)
+	return lo.Map(keys, func(key string, _ int) client.Object { return g.objects[key] })
+}
+
+// addReleasePlan adds the plan with its application, the components of the application,
+// the matched ReleasePlanAdmission, the releases created from the plan and their snapshots
+func (g *releaseGraph) addReleasePlan(ctx context.Context, releasePlan *konfluxapi.ReleasePlan) error {
+	if !g.add(releasePlan) {
+		return nil
+	}
+
+	if _, err := g.addApplication(ctx, releasePlan.Namespace, releasePlan.Spec.Application); err != nil {
+		return err
+	}
+
+	if rpaNamespace, rpaName, ok := strings.Cut(releasePlan.Status.ReleasePlanAdmission.Name, "/"); ok {
+		err := g.get(ctx, rpaNamespace, rpaName, &konfluxapi.ReleasePlanAdmission{})
+		// the managed namespace may not be readable by the tenant
+		if err != nil && !apierrors.IsForbidden(err) {
+			return err
+		}
+	}
+
+	releases, ok := g.releases[releasePlan.Namespace]
+	if !ok {
+		releaseList := &konfluxapi.ReleaseList{}
+		if err := g.k8sClient.List(ctx, releaseList, client.InNamespace(releasePlan.Namespace)); err != nil {
+			return err
+		}
+		releases = releaseList.Items
+		g.releases[releasePlan.Namespace] = releases
+	}
+
+	for idx := range releases {
+		if releases[idx].Spec.ReleasePlan != releasePlan.Name {
+			continue
+		}
+		release := releases[idx]
+		g.add(&release)
+		if err := g.get(ctx, release.Namespace, release.Spec.Snapshot, &applicationapi.Snapshot{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addApplication adds the application with its components. It returns false when the application is not found.
+func (g *releaseGraph) addApplication(ctx context.Context, namespace, application string) (bool, error) {
+	app := &applicationapi.Application{}
+	err := g.k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: application}, app)
+	if apierrors.IsNotFound(err) {
+		traceNote(ctx, "Application %s/%s not found", namespace, application)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !g.add(app) {
+		return true, nil
+	}
+
+	componentList := &applicationapi.ComponentList{}
+	if err := g.k8sClient.List(ctx, componentList, client.InNamespace(namespace)); err != nil {
+		return false, err
+	}
+	for idx := range componentList.Items {
+		if componentList.Items[idx].Spec.Application == application {
+			g.add(&componentList.Items[idx])
+		}
+	}
+	return true, nil
+}
+
+// ApplicationGraph returns the objects of the release graph of the application: the application and its
+// components, its release plans, the ReleasePlanAdmissions they matched, their releases and the released snapshots
+func ApplicationGraph(ctx context.Context, k8sClient client.Client, namespace, application string) ([]client.Object, error) {
+	g := newReleaseGraph(k8sClient)
+
+	found, err := g.addApplication(ctx, namespace, application)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("application %s/%s not found", namespace, application)
+	}
+
+	releasePlanList := &konfluxapi.ReleasePlanList{}
+	if err := k8sClient.List(ctx, releasePlanList, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for idx := range releasePlanList.Items {
+		if releasePlanList.Items[idx].Spec.Application != application {
+			continue
+		}
+		if err := g.addReleasePlan(ctx, &releasePlanList.Items[idx]); err != nil {
+			return nil, err
+		}
+	}
+
+	return g.sorted(), nil
+}
+
+// ReleasePlanAdmissionGraph returns the objects of the release graph of the ReleasePlanAdmission:
+// the ReleasePlanAdmission, the release plans it matched and, for each of them, the same objects as ApplicationGraph
+func ReleasePlanAdmissionGraph(ctx context.Context, k8sClient client.Client, namespace, name string) ([]client.Object, error) {
+	g := newReleaseGraph(k8sClient)
+
+	rpa := &konfluxapi.ReleasePlanAdmission{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, rpa); err != nil {
+		return nil, err
+	}
+	g.add(rpa)
+
+	for _, matched := range rpa.Status.ReleasePlans {
+		rpNamespace, rpName, ok := strings.Cut(matched.Name, "/")
+		if !ok {
+			continue
+		}
+		releasePlan := &konfluxapi.ReleasePlan{}
+		err := k8sClient.Get(ctx, client.ObjectKey{Namespace: rpNamespace, Name: rpName}, releasePlan)
+		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+			traceNote(ctx, "ReleasePlan %s not available: %s", matched.Name, apierrors.ReasonForError(err))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := g.addReleasePlan(ctx, releasePlan); err != nil {
+			return nil, err
+		}
+	}
+
+	return g.sorted(), nil
+}
@@ -0,0 +1,84 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Release graph export", func() {
+	var (
+		ctx       context.Context
+		k8sClient client.Client
+	)
+
+	objectNames := func(objs []client.Object) []string {
+		return lo.Map(objs, func(obj client.Object, _ int) string {
+			return fmt.Sprintf("%T %s/%s", obj, obj.GetNamespace(), obj.GetName())
+		})
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		t0 := time.Date(2025, time.May, 1, 10, 0, 0, 0, time.UTC)
+
+		releasePlan := testReleasePlan("my-rp", true)
+		releasePlan.Status.ReleasePlanAdmission.Name = testManagedNamespace + "/my-rpa"
+		otherPlan := testReleasePlan("other-rp", true)
+		otherPlan.Spec.Application = "other-application"
+
+		k8sClient = testClient(
+			testApplication(),
+			&applicationapi.Component{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-component", Namespace: testTenantNamespace},
+				Spec:       applicationapi.ComponentSpec{ComponentName: "my-component", Application: "my-application"},
+			},
+			releasePlan,
+			otherPlan,
+			testRPA("my-rpa", "my-rp"),
+			testRelease("release-a", "my-rp", "snapshot-a", true, t0),
+			// dangling snapshot reference
+			testRelease("release-b", "my-rp", "deleted-snapshot", false, t0),
+			testRelease("release-other", "other-rp", "snapshot-other", true, t0),
+			testSnapshot("snapshot-a", testDigest),
+			testSnapshot("snapshot-other", testOtherDigest),
+		)
+	})
+
+	It("collects the graph of an application", func() {
+		objs, err := ApplicationGraph(ctx, k8sClient, testTenantNamespace, "my-application")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(objectNames(objs)).To(Equal([]string{
+			"*v1alpha1.Application my-tenant/my-application",
+			"*v1alpha1.Component my-tenant/my-component",
+			"*v1alpha1.Release my-tenant/release-a",
+			"*v1alpha1.Release my-tenant/release-b",
+			"*v1alpha1.ReleasePlan my-tenant/my-rp",
+			"*v1alpha1.ReleasePlanAdmission rhtap-releng-tenant/my-rpa",
+			"*v1alpha1.Snapshot my-tenant/snapshot-a",
+		}))
+	})
+
+	It("collects the graph of a ReleasePlanAdmission", func() {
+		objs, err := ReleasePlanAdmissionGraph(ctx, k8sClient, testManagedNamespace, "my-rpa")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(objectNames(objs)).To(ContainElements(
+			"*v1alpha1.ReleasePlanAdmission rhtap-releng-tenant/my-rpa",
+			"*v1alpha1.ReleasePlan my-tenant/my-rp",
+			"*v1alpha1.Snapshot my-tenant/snapshot-a",
+		))
+		Expect(objectNames(objs)).ToNot(ContainElement(ContainSubstring("other")))
+	})
+
+	It("fails on unknown applications", func() {
+		_, err := ApplicationGraph(ctx, k8sClient, testTenantNamespace, "unknown")
+		Expect(err).To(MatchError("application my-tenant/unknown not found"))
+	})
+})
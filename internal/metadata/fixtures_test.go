@@ -0,0 +1,136 @@
+package metadata
+
+import (
+	"encoding/json"
+	"time"
+
+	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testTenantNamespace  = "my-tenant"
+	testManagedNamespace = "rhtap-releng-tenant"
+	testRepository       = "quay.io/org/my-app"
+	testDigest           = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	testOtherDigest      = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+func testScheme() *k8sruntime.Scheme {
+	scheme := k8sruntime.NewScheme()
+	Expect(konfluxapi.AddToScheme(scheme)).To(Succeed())
+	Expect(applicationapi.AddToScheme(scheme)).To(Succeed())
+	Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	return scheme
+}
+
+func testClient(objs ...client.Object) client.Client {
+	return fake.NewClientBuilder().WithScheme(testScheme()).WithObjects(objs...).Build()
+}
+
+func rawJSON(obj any) *k8sruntime.RawExtension {
+	data, err := json.Marshal(obj)
+	Expect(err).ToNot(HaveOccurred())
+	return &k8sruntime.RawExtension{Raw: data}
+}
+
+func testRPA(name string, releasePlans ...string) *konfluxapi.ReleasePlanAdmission {
+	rpa := &konfluxapi.ReleasePlanAdmission{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testManagedNamespace},
+		Spec: konfluxapi.ReleasePlanAdmissionSpec{
+			Applications: []string{"my-application"},
+			Origin:       testTenantNamespace,
+			Policy:       "standard",
+			Data: rawJSON(ReleasePlanAdmissionData{Mappping: ReleasePlanAdmissionDataMapping{
+				Components: []ReleasePlanAdmissionDataComponent{{
+					Name:         "my-component",
+					Repositories: []Repository{{Url: testRepository, Tags: []string{"latest", "1.0"}}},
+				}},
+			}}),
+		},
+	}
+	for _, rp := range releasePlans {
+		rpa.Status.ReleasePlans = append(rpa.Status.ReleasePlans,
+			konfluxapi.MatchedReleasePlan{Name: testTenantNamespace + "/" + rp})
+	}
+	return rpa
+}
+
+func testReleasePlan(name string, matched bool) *konfluxapi.ReleasePlan {
+	status := metav1.ConditionTrue
+	if !matched {
+		status = metav1.ConditionFalse
+	}
+	return &konfluxapi.ReleasePlan{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testTenantNamespace},
+		Spec: konfluxapi.ReleasePlanSpec{
+			Application: "my-application",
+			Target:      testManagedNamespace,
+		},
+		Status: konfluxapi.ReleasePlanStatus{
+			Conditions: []metav1.Condition{{
+				Type:   konfluxapi.MatchedConditionType.String(),
+				Status: status,
+				Reason: konfluxapi.MatchedReason.String(),
+			}},
+		},
+	}
+}
+
+func testRelease(name, releasePlan, snapshot string, released bool, completion time.Time) *konfluxapi.Release {
+	status := metav1.ConditionTrue
+	reason := konfluxapi.SucceededReason.String()
+	if !released {
+		status = metav1.ConditionFalse
+		reason = konfluxapi.FailedReason.String()
+	}
+	return &konfluxapi.Release{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testTenantNamespace},
+		Spec: konfluxapi.ReleaseSpec{
+			Snapshot:    snapshot,
+			ReleasePlan: releasePlan,
+		},
+		Status: konfluxapi.ReleaseStatus{
+			Artifacts: rawJSON(ReleaseArtifacts{Advisory: ReleaseAdvisory{
+				URL: "https://access.redhat.com/errata/" + name,
+			}}),
+			Conditions: []metav1.Condition{{
+				Type:   "Released",
+				Status: status,
+				Reason: reason,
+			}},
+			CompletionTime: &metav1.Time{Time: completion},
+		},
+	}
+}
+
+func testSnapshot(name, digest string) *applicationapi.Snapshot {
+	return &applicationapi.Snapshot{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testTenantNamespace},
+		Spec: applicationapi.SnapshotSpec{
+			Application: "my-application",
+			Components: []applicationapi.SnapshotComponent{{
+				Name:           "my-component",
+				ContainerImage: "quay.io/redhat-user-workloads/my-tenant/my-component@" + digest,
+				Source: applicationapi.ComponentSource{ComponentSourceUnion: applicationapi.ComponentSourceUnion{
+					GitSource: &applicationapi.GitSource{
+						URL:      "https://github.com/org/my-app",
+						Revision: "abc123",
+					},
+				}},
+			}},
+		},
+	}
+}
+
+func testApplication() *applicationapi.Application {
+	return &applicationapi.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-application", Namespace: testTenantNamespace},
+	}
+}
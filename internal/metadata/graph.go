@@ -0,0 +1,204 @@
+package metadata
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// graphClass styles the nodes and edges of the lineage graph
+type graphClass string
+
+const (
+	graphClassDefault graphClass = ""
+	// graphClassComplete is on a complete path
+	graphClassComplete graphClass = "complete"
+	// graphClassPruned is a candidate filtered out by the search
+	graphClassPruned graphClass = "pruned"
+)
+
+type graphNode struct {
+	id    string
+	label string
+	class graphClass
+}
+
+type graphEdge struct {
+	from, to string
+	// label is the reason of pruned edges
+	label string
+	class graphClass
+}
+
+// lineageGraph is the trace tree folded into a graph: objects visited several times,
+// e.g. a snapshot shipped by two releases, or visited and pruned, are one node
+type lineageGraph struct {
+	nodes []*graphNode
+	edges []*graphEdge
+
+	// byID indexes the nodes by object, see objectID. Labels are not unique across namespaces.
+	byID   map[string]*graphNode
+	byEdge map[string]*graphEdge
+}
+
+func newLineageGraph(t *Trace) *lineageGraph {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	g := &lineageGraph{byID: map[string]*graphNode{}, byEdge: map[string]*graphEdge{}}
+	root := g.node(t.Root.id, t.Root.Element, graphClassDefault)
+	if t.Root.onCompletePath() {
+		root.class = graphClassComplete
+	}
+	g.addChildren(root, t.Root)
+	return g
+}
+
+// onCompletePath returns true when some leaf under the node ends a complete path
+func (n *TraceNode) onCompletePath() bool {
+	if n.Complete {
+		return true
+	}
+	for _, child := range n.Children {
+		if child.onCompletePath() {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *lineageGraph) addChildren(parent *graphNode, n *TraceNode) {
+	// children are appended concurrently by the search, sort them for a stable output
+	children := append([]*TraceNode{}, n.Children...)
+	sort.SliceStable(children, func(i, j int) bool { return children[i].Element < children[j].Element })
+
+	for _, child := range children {
+		class := graphClassDefault
+		if child.onCompletePath() {
+			class = graphClassComplete
+		}
+		node := g.node(child.id, child.Element, class)
+		g.edge(parent, node, "", class)
+		g.addChildren(node, child)
+	}
+
+	filtered := append([]TraceFilter{}, n.Filtered...)
+	sort.SliceStable(filtered, func(i, j int) bool { return filtered[i].Candidate < filtered[j].Candidate })
+
+	for _, filter := range filtered {
+		node := g.node(filter.id, filter.Candidate, graphClassPruned)
+		g.edge(parent, node, filter.Reason, graphClassPruned)
+	}
+}
+
+// node returns the node of the object, adding it with the label when missing. Complete wins over other classes.
+func (g *lineageGraph) node(id, label string, class graphClass) *graphNode {
+	if node, ok := g.byID[id]; ok {
+		if class == graphClassComplete {
+			node.class = class
+		}
+		return node
+	}
+	node := &graphNode{id: fmt.Sprintf("n%d", len(g.nodes)), label: label, class: class}
+	g.nodes = append(g.nodes, node)
+	g.byID[id] = node
+	return node
+}
+
+func (g *lineageGraph) edge(from, to *graphNode, label string, class graphClass) {
+	key := from.id + "->" + to.id
+	if edge, ok := g.byEdge[key]; ok {
+		if class == graphClassComplete {
+			edge.class = class
+		}
+		return
+	}
+	edge := &graphEdge{from: from.id, to: to.id, label: label, class: class}
+	g.edges = append(g.edges, edge)
+	g.byEdge[key] = edge
+}
+
+// ToDOT renders the explored lineage graph in Graphviz DOT: complete paths are green,
+// pruned candidates are grey and dashed, with the reason on the edge
+func (t *Trace) ToDOT() string {
+	g := newLineageGraph(t)
+
+	var b strings.Builder
+	b.WriteString("digraph lineage {\n  rankdir=LR;\n  node [shape=box, style=rounded];\n")
+	for _, node := range g.nodes {
+		fmt.Fprintf(&b, "  %s [label=%s%s];\n", node.id, dotQuote(node.label), dotStyle(node.class, true))
+	}
+	for _, edge := range g.edges {
+		label := ""
+		if edge.label != "" {
+			label = ", label=" + dotQuote(edge.label)
+		}
+		fmt.Fprintf(&b, "  %s -> %s [%s%s];\n", edge.from, edge.to, strings.TrimPrefix(dotStyle(edge.class, false), ", "), label)
+	}
+	b.WriteString("}")
+	return strings.ReplaceAll(b.String(), " [];", ";")
+}
+
+func dotStyle(class graphClass, node bool) string {
+	switch class {
+	case graphClassComplete:
+		return ", color=darkgreen, penwidth=2"
+	case graphClassPruned:
+		if node {
+			return `, style="rounded,dashed", color=gray, fontcolor=gray`
+		}
+		return ", style=dashed, color=gray, fontcolor=gray"
+	}
+	return ""
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// ToMermaid renders the explored lineage graph as a Mermaid flowchart, styled like ToDOT
+func (t *Trace) ToMermaid() string {
+	g := newLineageGraph(t)
+
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, node := range g.nodes {
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", node.id, mermaidEscape(node.label))
+	}
+
+	completeEdges := []string{}
+	for idx, edge := range g.edges {
+		switch {
+		case edge.class == graphClassPruned:
+			fmt.Fprintf(&b, "  %s -.->|\"%s\"| %s\n", edge.from, mermaidEscape(edge.label), edge.to)
+		default:
+			fmt.Fprintf(&b, "  %s --> %s\n", edge.from, edge.to)
+		}
+		if edge.class == graphClassComplete {
+			completeEdges = append(completeEdges, fmt.Sprint(idx))
+		}
+	}
+
+	b.WriteString("  classDef complete stroke:darkgreen,stroke-width:3px\n")
+	b.WriteString("  classDef pruned fill:#eeeeee,stroke:gray,color:gray,stroke-dasharray:5 5\n")
+	for _, class := range []graphClass{graphClassComplete, graphClassPruned} {
+		ids := []string{}
+		for _, node := range g.nodes {
+			if node.class == class {
+				ids = append(ids, node.id)
+			}
+		}
+		if len(ids) > 0 {
+			fmt.Fprintf(&b, "  class %s %s\n", strings.Join(ids, ","), class)
+		}
+	}
+	if len(completeEdges) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:darkgreen,stroke-width:3px\n", strings.Join(completeEdges, ","))
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s)
+}
@@ -0,0 +1,122 @@
+package metadata
+
+import (
+	"context"
+	"time"
+
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/eguzki/konfluxctl/internal/utils"
+)
+
+var _ = Describe("Trace graph", func() {
+	var trace *Trace
+
+	BeforeEach(func() {
+		t0 := time.Date(2025, time.May, 1, 10, 0, 0, 0, time.UTC)
+		k8sClient := testClient(
+			testRPA("my-rpa", "my-rp", "my-rp-unmatched"),
+			testReleasePlan("my-rp", true),
+			testReleasePlan("my-rp-unmatched", false),
+			testRelease("release-ga", "my-rp", "snapshot-a", true, t0),
+			testRelease("release-z", "my-rp", "snapshot-a", true, t0.Add(time.Hour)),
+			testRelease("release-failed", "my-rp", "snapshot-a", false, t0.Add(2*time.Hour)),
+			testRelease("release-other", "my-rp", "snapshot-other", true, t0.Add(3*time.Hour)),
+			testSnapshot("snapshot-a", testDigest),
+			testSnapshot("snapshot-other", testOtherDigest),
+			testApplication(),
+		)
+
+		imageURL, err := utils.ParseImageURL(testRepository + "@" + testDigest)
+		Expect(err).ToNot(HaveOccurred())
+
+		trace = NewTrace("Image: " + testRepository)
+		ctx := WithTrace(context.Background(), trace)
+
+		rpaList, err := ReleasePlanAdmissionList(ctx, k8sClient, imageURL.FamiliarName(), nil)
+		Expect(err).ToNot(HaveOccurred())
+		paths, err := DepthFirstSearch(ctx, k8sClient, imageURL, rpaList)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(paths).To(HaveLen(2))
+	})
+
+	It("folds the trace into a graph", func() {
+		g := newLineageGraph(trace)
+
+		classes := map[string]graphClass{}
+		for _, node := range g.nodes {
+			classes[node.label] = node.class
+		}
+		Expect(classes).To(HaveKeyWithValue("Image: "+testRepository, graphClassComplete))
+		Expect(classes).To(HaveKeyWithValue("ReleasePlanAdmission: my-rpa", graphClassComplete))
+		Expect(classes).To(HaveKeyWithValue("Release: release-z", graphClassComplete))
+		Expect(classes).To(HaveKeyWithValue("Release: release-other", graphClassDefault))
+		Expect(classes).To(HaveKeyWithValue("ReleasePlan my-tenant/my-rp-unmatched", graphClassPruned))
+		Expect(classes).To(HaveKeyWithValue("Release release-failed", graphClassPruned))
+
+		// snapshot-a is shipped by two releases, it is one node with two incoming edges
+		snapshots := 0
+		for label := range classes {
+			if label == "Snapshot: snapshot-a" {
+				snapshots++
+			}
+		}
+		Expect(snapshots).To(Equal(1))
+		snapshot := g.byID[objectID("Snapshot", testTenantNamespace, "snapshot-a")]
+		incoming := 0
+		for _, edge := range g.edges {
+			if edge.to == snapshot.id {
+				incoming++
+			}
+		}
+		Expect(incoming).To(Equal(2))
+	})
+
+	It("renders Graphviz DOT", func() {
+		dot := trace.ToDOT()
+		Expect(dot).To(HavePrefix("digraph lineage {"))
+		Expect(dot).To(HaveSuffix("}"))
+		Expect(dot).To(ContainSubstring(`[label="ReleasePlanAdmission: my-rpa", color=darkgreen, penwidth=2];`))
+		Expect(dot).To(ContainSubstring(`[label="Release release-failed", style="rounded,dashed", color=gray, fontcolor=gray];`))
+		Expect(dot).To(ContainSubstring(`style=dashed, color=gray, fontcolor=gray, label="condition Released is False (reason: Failed)"];`))
+		Expect(trace.ToDOT()).To(Equal(dot))
+	})
+
+	It("renders a Mermaid flowchart", func() {
+		mermaid := trace.ToMermaid()
+		Expect(mermaid).To(HavePrefix("flowchart LR\n"))
+		Expect(mermaid).To(ContainSubstring(`["ReleasePlanAdmission: my-rpa"]`))
+		Expect(mermaid).To(ContainSubstring(`-.->|"condition Matched is False (reason: Matched)"|`))
+		Expect(mermaid).To(ContainSubstring("classDef pruned"))
+		Expect(mermaid).To(MatchRegexp(`class (n\d+,)*n\d+ complete`))
+		Expect(mermaid).To(ContainSubstring("linkStyle "))
+		Expect(trace.ToMermaid()).To(Equal(mermaid))
+	})
+})
+
+var _ = Describe("Trace graph nodes", func() {
+	release := func(namespace, name string) Element {
+		return &ReleaseElement{Release: konfluxapi.Release{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}}
+	}
+
+	It("keys nodes by object, not by label", func() {
+		trace := NewTrace("Image: " + testRepository)
+		rp := trace.Root.addChild(&ReleasePlanElement{ReleasePlan: *testReleasePlan("my-rp", true)})
+		// same name, same label, other tenants
+		rp.addChild(release("tenant-a", "release-1"))
+		rp.addChild(release("tenant-b", "release-1"))
+		// visited from one plan, pruned from the other: one object, two labels
+		rp.addChild(release("tenant-a", "release-2"))
+		traceFilter(withTraceNode(context.Background(), rp), objectID("Release", "tenant-a", "release-2"), "Release release-2", "some reason")
+
+		g := newLineageGraph(trace)
+		Expect(lo.Map(g.nodes, func(n *graphNode, _ int) string { return n.label })).To(Equal([]string{
+			"Image: " + testRepository, "ReleasePlan: my-rp", "Release: release-1", "Release: release-1", "Release: release-2",
+		}))
+		Expect(g.byID).To(HaveKey(objectID("Release", "tenant-b", "release-1")))
+	})
+})
@@ -0,0 +1,202 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	"github.com/samber/lo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/eguzki/konfluxctl/internal/utils"
+)
+
+// Severity of a lint finding
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is one problem of the release configuration
+type Finding struct {
+	Severity Severity `json:"severity"`
+	// Object is the kind and the namespaced name of the object with the problem
+	Object  string `json:"object"`
+	Message string `json:"message"`
+}
+
+// LintReport gathers the findings of the release configuration of one application
+type LintReport struct {
+	Application string    `json:"application"`
+	Namespace   string    `json:"namespace"`
+	Findings    []Finding `json:"findings"`
+}
+
+func (r *LintReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Application: %s/%s\n", r.Namespace, r.Application)
+
+	if len(r.Findings) > 0 {
+		w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "SEVERITY\tOBJECT\tMESSAGE")
+		for _, finding := range r.Findings {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", finding.Severity, finding.Object, finding.Message)
+		}
+		_ = w.Flush()
+	}
+
+	fmt.Fprintf(&b, "%d errors, %d warnings", r.count(SeverityError), r.count(SeverityWarning))
+	return b.String()
+}
+
+// HasErrors returns true when some finding is an error
+func (r *LintReport) HasErrors() bool {
+	return r.count(SeverityError) > 0
+}
+
+func (r *LintReport) count(severity Severity) int {
+	return lo.CountBy(r.Findings, func(f Finding) bool { return f.Severity == severity })
+}
+
+func (r *LintReport) add(severity Severity, object, format string, args ...any) {
+	r.Findings = append(r.Findings, Finding{Severity: severity, Object: object, Message: fmt.Sprintf(format, args...)})
+}
+
+// LintApplication checks the release configuration of the application, the things
+// the lineage search silently filters out:
+// every ReleasePlan of the application is Matched, the ReleasePlanAdmission it matched
+// maps every component of the application, mapped repositories are valid references
+// and have tags. Errors are findings, not returned errors, unless the cluster cannot be read.
+func LintApplication(ctx context.Context, k8sClient client.Client, namespace, application string) (*LintReport, error) {
+	report := &LintReport{Application: application, Namespace: namespace, Findings: []Finding{}}
+	applicationObject := fmt.Sprintf("Application %s/%s", namespace, application)
+
+	err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: application}, &applicationapi.Application{})
+	if apierrors.IsNotFound(err) {
+		report.add(SeverityError, applicationObject, "application not found")
+		return report, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	componentList := &applicationapi.ComponentList{}
+	if err := k8sClient.List(ctx, componentList, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	components := lo.FilterMap(componentList.Items, func(c applicationapi.Component, _ int) (string, bool) {
+		return c.Name, c.Spec.Application == application
+	})
+	sort.Strings(components)
+	if len(components) == 0 {
+		report.add(SeverityWarning, applicationObject, "application has no components")
+	}
+
+	releasePlanList := &konfluxapi.ReleasePlanList{}
+	if err := k8sClient.List(ctx, releasePlanList, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	releasePlans := lo.Filter(releasePlanList.Items, func(rp konfluxapi.ReleasePlan, _ int) bool {
+		return rp.Spec.Application == application
+	})
+	sort.Slice(releasePlans, func(i, j int) bool { return releasePlans[i].Name < releasePlans[j].Name })
+	if len(releasePlans) == 0 {
+		report.add(SeverityWarning, applicationObject, "application has no ReleasePlan")
+	}
+
+	// several plans may match the same ReleasePlanAdmission, lint it once
+	linted := map[string]bool{}
+
+	for _, releasePlan := range releasePlans {
+		releasePlanObject := fmt.Sprintf("ReleasePlan %s/%s", releasePlan.Namespace, releasePlan.Name)
+		if !meta.IsStatusConditionTrue(releasePlan.Status.Conditions, konfluxapi.MatchedConditionType.String()) {
+			report.add(SeverityError, releasePlanObject, "not matched: %s",
+				conditionNotTrueReason(releasePlan.Status.Conditions, konfluxapi.MatchedConditionType.String()))
+			continue
+		}
+
+		rpaName := releasePlan.Status.ReleasePlanAdmission.Name
+		if linted[rpaName] {
+			continue
+		}
+		linted[rpaName] = true
+
+		rpaNamespace, name, ok := strings.Cut(rpaName, "/")
+		if !ok {
+			report.add(SeverityError, releasePlanObject, "matched ReleasePlanAdmission %q is not a namespaced name", rpaName)
+			continue
+		}
+
+		rpa := &konfluxapi.ReleasePlanAdmission{}
+		err := k8sClient.Get(ctx, client.ObjectKey{Namespace: rpaNamespace, Name: name}, rpa)
+		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+			report.add(SeverityWarning, releasePlanObject, "matched ReleasePlanAdmission %s cannot be read: %s", rpaName, apierrors.ReasonForError(err))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		lintReleasePlanAdmission(report, rpa, components)
+	}
+
+	return report, nil
+}
+
+func lintReleasePlanAdmission(report *LintReport, rpa *konfluxapi.ReleasePlanAdmission, components []string) {
+	rpaObject := fmt.Sprintf("ReleasePlanAdmission %s/%s", rpa.Namespace, rpa.Name)
+
+	summary := summarizeReleasePlanAdmission(rpa)
+	if summary.DataError != "" {
+		report.add(SeverityError, rpaObject, "spec.data cannot be parsed: %s", summary.DataError)
+		return
+	}
+
+	mapped := lo.KeyBy(summary.Components, func(c ReleasePlanAdmissionDataComponent) string { return c.Name })
+
+	for _, component := range components {
+		if _, ok := mapped[component]; !ok {
+			report.add(SeverityError, rpaObject, "component %s has no mapping entry", component)
+		}
+	}
+
+	for _, mapping := range summary.Components {
+		if !lo.Contains(components, mapping.Name) {
+			report.add(SeverityWarning, rpaObject, "mapping entry %s is not a component of the application", mapping.Name)
+			continue
+		}
+
+		if len(mapping.Repositories) == 0 {
+			report.add(SeverityError, rpaObject, "component %s has no repositories", mapping.Name)
+		}
+
+		for _, repository := range mapping.Repositories {
+			if _, err := utils.ParseRepository(repository.Url); err != nil {
+				report.add(SeverityError, rpaObject, "component %s: repository %q is not valid: %s", mapping.Name, repository.Url, err)
+				continue
+			}
+
+			if hasTagOrDigest(repository.Url) {
+				report.add(SeverityWarning, rpaObject, "component %s: repository %q should have no tag nor digest", mapping.Name, repository.Url)
+			}
+
+			// image metadata requires the tags of the path by default
+			if len(repository.Tags) == 0 {
+				report.add(SeverityError, rpaObject, "component %s: repository %s has no tags", mapping.Name, repository.Url)
+			}
+		}
+	}
+}
+
+func hasTagOrDigest(repository string) bool {
+	lastSegment := repository[strings.LastIndex(repository, "/")+1:]
+	return strings.Contains(repository, "@") || strings.Contains(lastSegment, ":")
+}
@@ -0,0 +1,96 @@
+package metadata
+
+import (
+	"context"
+
+	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("LintApplication", func() {
+	var (
+		ctx         context.Context
+		releasePlan *konfluxapi.ReleasePlan
+		rpa         *konfluxapi.ReleasePlanAdmission
+	)
+
+	testComponent := func(name string) *applicationapi.Component {
+		return &applicationapi.Component{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testTenantNamespace},
+			Spec:       applicationapi.ComponentSpec{ComponentName: name, Application: "my-application"},
+		}
+	}
+
+	lint := func(objs ...client.Object) *LintReport {
+		report, err := LintApplication(ctx, testClient(objs...), testTenantNamespace, "my-application")
+		Expect(err).ToNot(HaveOccurred())
+		return report
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		releasePlan = testReleasePlan("my-rp", true)
+		releasePlan.Status.ReleasePlanAdmission.Name = testManagedNamespace + "/my-rpa"
+		rpa = testRPA("my-rpa", "my-rp")
+	})
+
+	It("reports nothing on a valid configuration", func() {
+		report := lint(testApplication(), testComponent("my-component"), releasePlan, rpa)
+		Expect(report.Findings).To(BeEmpty())
+		Expect(report.HasErrors()).To(BeFalse())
+		Expect(report.String()).To(HaveSuffix("0 errors, 0 warnings"))
+	})
+
+	It("reports a missing application", func() {
+		report := lint()
+		Expect(report.Findings).To(Equal([]Finding{{
+			Severity: SeverityError, Object: "Application my-tenant/my-application", Message: "application not found",
+		}}))
+	})
+
+	It("reports unmatched release plans", func() {
+		report := lint(testApplication(), testComponent("my-component"), testReleasePlan("unmatched-rp", false))
+		Expect(report.HasErrors()).To(BeTrue())
+		Expect(report.Findings).To(ConsistOf(Finding{
+			Severity: SeverityError, Object: "ReleasePlan my-tenant/unmatched-rp",
+			Message: "not matched: condition Matched is False (reason: Matched)",
+		}))
+	})
+
+	It("reports mapping problems of the matched ReleasePlanAdmission", func() {
+		rpa.Spec.Data = rawJSON(ReleasePlanAdmissionData{Mappping: ReleasePlanAdmissionDataMapping{
+			Components: []ReleasePlanAdmissionDataComponent{
+				{Name: "my-component", Repositories: []Repository{
+					{Url: "quay.io/Org/Invalid", Tags: []string{"latest"}},
+					{Url: "quay.io/org/my-app:1.0"},
+				}},
+				{Name: "removed-component", Repositories: []Repository{{Url: testRepository}}},
+			},
+		}})
+
+		rpaObject := "ReleasePlanAdmission " + testManagedNamespace + "/my-rpa"
+		report := lint(testApplication(), testComponent("my-component"), testComponent("new-component"), releasePlan, rpa)
+		Expect(report.Findings).To(HaveLen(5))
+		Expect(report.Findings).To(ContainElements(
+			Finding{Severity: SeverityError, Object: rpaObject, Message: "component new-component has no mapping entry"},
+			Finding{Severity: SeverityWarning, Object: rpaObject, Message: "mapping entry removed-component is not a component of the application"},
+			Finding{Severity: SeverityWarning, Object: rpaObject, Message: `component my-component: repository "quay.io/org/my-app:1.0" should have no tag nor digest`},
+			Finding{Severity: SeverityError, Object: rpaObject, Message: "component my-component: repository quay.io/org/my-app:1.0 has no tags"},
+		))
+		Expect(report.Findings).To(ContainElement(And(
+			HaveField("Severity", SeverityError),
+			HaveField("Message", ContainSubstring(`repository "quay.io/Org/Invalid" is not valid`)),
+		)))
+		Expect(report.String()).To(HaveSuffix("3 errors, 2 warnings"))
+	})
+
+	It("reports unreadable ReleasePlanAdmissions", func() {
+		report := lint(testApplication(), testComponent("my-component"), releasePlan)
+		Expect(report.Findings).To(ConsistOf(HaveField("Severity", SeverityWarning)))
+		Expect(report.HasErrors()).To(BeFalse())
+	})
+})
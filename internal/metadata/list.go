@@ -0,0 +1,69 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// listInNamespaces lists objects in the given namespaces, one list per namespace.
+// When no namespace is given, objects are listed across all namespaces the user can read.
+func listInNamespaces[L client.ObjectList](ctx context.Context, k8sClient client.Client, namespaces []string, newList func() L) ([]L, error) {
+	if len(namespaces) == 0 {
+		return listAllNamespaces(ctx, k8sClient, newList)
+	}
+
+	result := []L{}
+	for _, namespace := range lo.Uniq(namespaces) {
+		list := newList()
+		if err := k8sClient.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		result = append(result, list)
+	}
+
+	return result, nil
+}
+
+// listAllNamespaces tries a cluster wide list first. When it is not allowed,
+// it falls back to listing on each readable namespace.
+func listAllNamespaces[L client.ObjectList](ctx context.Context, k8sClient client.Client, newList func() L) ([]L, error) {
+	list := newList()
+	err := k8sClient.List(ctx, list)
+	if err == nil {
+		return []L{list}, nil
+	}
+
+	if !apierrors.IsForbidden(err) {
+		return nil, err
+	}
+
+	slog.Debug("cluster wide list forbidden, listing per namespace", "list", fmt.Sprintf("%T", list))
+
+	namespaceList := &corev1.NamespaceList{}
+	if nsErr := k8sClient.List(ctx, namespaceList); nsErr != nil {
+		slog.Debug("listing namespaces", "error", nsErr)
+		return nil, err
+	}
+
+	result := []L{}
+	for _, namespace := range namespaceList.Items {
+		nsList := newList()
+		nsErr := k8sClient.List(ctx, nsList, client.InNamespace(namespace.Name))
+		if apierrors.IsForbidden(nsErr) {
+			slog.Debug("list forbidden", "namespace", namespace.Name)
+			continue
+		}
+		if nsErr != nil {
+			return nil, nsErr
+		}
+		result = append(result, nsList)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,59 @@
+package metadata
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/eguzki/konfluxctl/internal/utils"
+)
+
+// ManifestIndex maps the platform manifests of multi-arch images to their image index.
+// Implementations must be safe for concurrent use.
+type ManifestIndex interface {
+	// PlatformManifests returns the platform of each manifest of the image index, keyed by manifest digest.
+	// It returns an empty map when the image is a single platform manifest.
+	PlatformManifests(ctx context.Context, imageURL *utils.ImageURL) (map[string]string, error)
+}
+
+type manifestIndexKey struct{}
+
+// WithManifestIndex returns a copy of the context matching image digests across image indexes
+// and their platform manifests. Without it, only identical digests match.
+func WithManifestIndex(ctx context.Context, index ManifestIndex) context.Context {
+	return context.WithValue(ctx, manifestIndexKey{}, index)
+}
+
+func manifestIndexFromContext(ctx context.Context) ManifestIndex {
+	index, _ := ctx.Value(manifestIndexKey{}).(ManifestIndex)
+	return index
+}
+
+// matchPlatform returns true when one of the images is a platform manifest of the other one, which is an image index.
+// The platform of the manifest is returned as well.
+func matchPlatform(ctx context.Context, componentImageURL, imageURL *utils.ImageURL) (string, bool) {
+	index := manifestIndexFromContext(ctx)
+	if index == nil {
+		return "", false
+	}
+
+	// the queried image is the index, the component was recorded by platform
+	if platform, ok := platformManifest(ctx, index, imageURL, componentImageURL.Digest()); ok {
+		return platform, true
+	}
+
+	// the component was recorded by index, usual Konflux multi-arch builds
+	return platformManifest(ctx, index, componentImageURL, imageURL.Digest())
+}
+
+func platformManifest(ctx context.Context, index ManifestIndex, indexURL *utils.ImageURL, digest string) (string, bool) {
+	manifests, err := index.PlatformManifests(ctx, indexURL)
+	if err != nil {
+		// the registry may not be reachable, or readable, by the user. Not fatal, the component does not match.
+		slog.Debug("fetching image index", "image", indexURL.FamiliarName(), "digest", indexURL.Digest(), "error", err)
+		traceNote(ctx, "image index %s@%s not available: %s", indexURL.FamiliarName(), indexURL.Digest(), err)
+		return "", false
+	}
+
+	platform, ok := manifests[digest]
+	return platform, ok
+}
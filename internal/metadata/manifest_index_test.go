@@ -0,0 +1,133 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+
+	"github.com/eguzki/konfluxctl/internal/utils"
+)
+
+const testUnreachableDigest = "sha256:3333333333333333333333333333333333333333333333333333333333333333"
+
+// fakeManifestIndex maps image index digests to their platform manifests
+type fakeManifestIndex map[string]map[string]string
+
+func (f fakeManifestIndex) PlatformManifests(_ context.Context, imageURL *utils.ImageURL) (map[string]string, error) {
+	if imageURL.Digest() == testUnreachableDigest {
+		return nil, errors.New("unauthorized")
+	}
+	return f[imageURL.Digest()], nil
+}
+
+// recordingManifestIndex records the image indexes fetched
+type recordingManifestIndex struct {
+	index   ManifestIndex
+	mu      sync.Mutex
+	fetched []string
+}
+
+func (r *recordingManifestIndex) PlatformManifests(ctx context.Context, imageURL *utils.ImageURL) (map[string]string, error) {
+	r.mu.Lock()
+	r.fetched = append(r.fetched, imageURL.Digest())
+	r.mu.Unlock()
+	return r.index.PlatformManifests(ctx, imageURL)
+}
+
+var _ = Describe("DepthFirstSearch with image indexes", func() {
+	var (
+		ctx       context.Context
+		t0        time.Time
+		indexFake fakeManifestIndex
+	)
+
+	BeforeEach(func() {
+		t0 = time.Date(2025, time.May, 1, 10, 0, 0, 0, time.UTC)
+		// testOtherDigest is the image index, testDigest its linux/arm64 manifest
+		indexFake = fakeManifestIndex{testOtherDigest: {testDigest: "linux/arm64"}}
+		ctx = WithManifestIndex(context.Background(), indexFake)
+	})
+
+	search := func(ctx context.Context, digest string) []Path {
+		imageURL, err := utils.ParseImageURL(testRepository + "@" + digest)
+		Expect(err).ToNot(HaveOccurred())
+
+		k8sClient := testClient(
+			testRPA("my-rpa", "my-rp"),
+			testReleasePlan("my-rp", true),
+			testRelease("release-index", "my-rp", "snapshot-index", true, t0),
+			testRelease("release-platform", "my-rp", "snapshot-platform", true, t0.Add(time.Hour)),
+			testRelease("release-unreachable", "my-rp", "snapshot-unreachable", true, t0.Add(2*time.Hour)),
+			testSnapshot("snapshot-index", testOtherDigest),
+			testSnapshot("snapshot-platform", testDigest),
+			testSnapshot("snapshot-unreachable", testUnreachableDigest),
+			testApplication(),
+		)
+
+		rpaList, err := ReleasePlanAdmissionList(ctx, k8sClient, imageURL.FamiliarName(), []string{testManagedNamespace})
+		Expect(err).ToNot(HaveOccurred())
+
+		paths, err := DepthFirstSearch(ctx, k8sClient, imageURL, rpaList)
+		Expect(err).ToNot(HaveOccurred())
+		return paths
+	}
+
+	It("matches platform manifests with the snapshot image index", func() {
+		paths := search(ctx, testDigest)
+		Expect(lo.Map(paths, func(p Path, _ int) string { return *p.Release })).To(
+			Equal([]string{"release-platform", "release-index"}))
+		Expect(paths[0].Platform).To(BeNil())
+		Expect(paths[1].Platform).To(HaveValue(Equal("linux/arm64")))
+	})
+
+	It("matches image indexes with the snapshot platform manifest", func() {
+		paths := search(ctx, testOtherDigest)
+		Expect(lo.Map(paths, func(p Path, _ int) string { return *p.Release })).To(
+			Equal([]string{"release-platform", "release-index"}))
+		Expect(paths[0].Platform).To(HaveValue(Equal("linux/arm64")))
+		Expect(paths[1].Platform).To(BeNil())
+	})
+
+	It("fetches the image index of the components mapped to the image repository only", func() {
+		imageURL, err := utils.ParseImageURL(testRepository + "@" + testDigest)
+		Expect(err).ToNot(HaveOccurred())
+
+		// the other component is built as the image index, but released to another repository
+		snapshot := testSnapshot("snapshot-other", testUnreachableDigest)
+		snapshot.Spec.Components = append([]applicationapi.SnapshotComponent{{
+			Name:           "other-component",
+			ContainerImage: "quay.io/redhat-user-workloads/my-tenant/other-component@" + testOtherDigest,
+		}}, snapshot.Spec.Components...)
+
+		k8sClient := testClient(
+			testRPA("my-rpa", "my-rp"),
+			testReleasePlan("my-rp", true),
+			testRelease("release-other", "my-rp", "snapshot-other", true, t0),
+			snapshot,
+			testApplication(),
+		)
+
+		index := &recordingManifestIndex{index: indexFake}
+		ctx := WithManifestIndex(context.Background(), index)
+
+		rpaList, err := ReleasePlanAdmissionList(ctx, k8sClient, imageURL.FamiliarName(), []string{testManagedNamespace})
+		Expect(err).ToNot(HaveOccurred())
+
+		paths, err := DepthFirstSearch(ctx, k8sClient, imageURL, rpaList)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(paths).To(BeEmpty())
+		Expect(index.fetched).NotTo(ContainElement(testOtherDigest))
+	})
+
+	It("matches identical digests only without manifest index", func() {
+		paths := search(context.Background(), testDigest)
+		Expect(lo.Map(paths, func(p Path, _ int) string { return *p.Release })).To(
+			Equal([]string{"release-platform"}))
+	})
+})
@@ -0,0 +1,40 @@
+package metadata
+
+import (
+	"time"
+
+	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// releaseServiceName is the name printed by -o name, kind.group/name like kubectl
+func releaseServiceName(kind, name string) string {
+	return kind + "." + konfluxapi.GroupVersion.Group + "/" + name
+}
+
+func applicationName(kind, name string) string {
+	return kind + "." + applicationapi.GroupVersion.Group + "/" + name
+}
+
+// cell renders empty table cells as "-"
+func cell(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func durationCell(d *metav1.Duration) string {
+	if d == nil {
+		return "-"
+	}
+	return d.Round(time.Second).String()
+}
+
+func timeCell(t *metav1.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,95 @@
+package metadata
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// FieldChange is one path field with different values for two images
+type FieldChange struct {
+	Field Field  `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// PathDiff compares the primary paths of two images
+type PathDiff struct {
+	From    string        `json:"from"`
+	To      string        `json:"to"`
+	Changes []FieldChange `json:"changes"`
+	// CompareURL shows the source changes between both revisions. Only set for GitHub and GitLab repositories.
+	CompareURL *string `json:"compareURL,omitempty"`
+}
+
+func (d *PathDiff) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", d.From, d.To)
+
+	if len(d.Changes) == 0 {
+		b.WriteString("Same lineage")
+		return b.String()
+	}
+
+	for _, change := range d.Changes {
+		fmt.Fprintf(&b, "~ %s: %s -> %s\n", change.Field, orNone(change.From), orNone(change.To))
+	}
+
+	if d.CompareURL != nil {
+		fmt.Fprintf(&b, "Compare: %s\n", *d.CompareURL)
+	}
+
+	fmt.Fprintf(&b, "%d fields differ", len(d.Changes))
+	return b.String()
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
+
+// DiffPaths compares every path field of the from and to images
+func DiffPaths(fromImage string, from Path, toImage string, to Path) *PathDiff {
+	diff := &PathDiff{
+		From:    fromImage,
+		To:      toImage,
+		Changes: []FieldChange{},
+	}
+
+	for _, field := range AllFields {
+		fromValue, toValue := from.value(field), to.value(field)
+		if field == FieldSourceURL && normalizeGitURL(fromValue) == normalizeGitURL(toValue) {
+			continue
+		}
+		if fromValue != toValue {
+			diff.Changes = append(diff.Changes, FieldChange{Field: field, From: fromValue, To: toValue})
+		}
+	}
+
+	fromRevision, toRevision := lo.FromPtr(from.SourceRevision), lo.FromPtr(to.SourceRevision)
+	if fromRevision != "" && toRevision != "" && fromRevision != toRevision &&
+		normalizeGitURL(lo.FromPtr(from.SourceURL)) == normalizeGitURL(lo.FromPtr(to.SourceURL)) {
+		if compareURL, ok := GitCompareURL(lo.FromPtr(to.SourceURL), fromRevision, toRevision); ok {
+			diff.CompareURL = &compareURL
+		}
+	}
+
+	return diff
+}
+
+// GitCompareURL returns the web page comparing two revisions of a GitHub or GitLab repository
+func GitCompareURL(sourceURL, fromRevision, toRevision string) (string, bool) {
+	repo := normalizeGitURL(sourceURL)
+	host, _, _ := strings.Cut(repo, "/")
+
+	switch {
+	case host == "github.com":
+		return fmt.Sprintf("https://%s/compare/%s...%s", repo, fromRevision, toRevision), true
+	case strings.HasPrefix(host, "gitlab."):
+		return fmt.Sprintf("https://%s/-/compare/%s...%s", repo, fromRevision, toRevision), true
+	}
+	return "", false
+}
@@ -0,0 +1,70 @@
+package metadata
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("DiffPaths", func() {
+	var from, to Path
+
+	BeforeEach(func() {
+		from = Path{
+			ReleasePlanAdmission: ptr.To("my-rpa"),
+			ReleasePlan:          ptr.To("my-rp"),
+			Release:              ptr.To("release-a"),
+			Application:          ptr.To("my-application"),
+			SourceRevision:       ptr.To("abc123"),
+			SourceURL:            ptr.To("https://github.com/org/my-app"),
+			Snapshot:             ptr.To("snapshot-a"),
+			ComponentName:        ptr.To("my-component"),
+			ImageTags:            []string{"1.0"},
+		}
+		to = from.Clone()
+	})
+
+	It("reports no change for the same lineage", func() {
+		diff := DiffPaths("a", from, "b", to)
+		Expect(diff.Changes).To(BeEmpty())
+		Expect(diff.CompareURL).To(BeNil())
+		Expect(diff.String()).To(HaveSuffix("Same lineage"))
+	})
+
+	It("reports the changed fields with the compare URL", func() {
+		to.SourceRevision = ptr.To("def456")
+		to.SourceURL = ptr.To("git@github.com:org/my-app.git")
+		to.Snapshot = ptr.To("snapshot-b")
+		to.Release = ptr.To("release-b")
+		to.ImageTags = []string{"1.1", "latest"}
+		to.Advisory = ptr.To("https://access.redhat.com/errata/release-b")
+
+		diff := DiffPaths("a", from, "b", to)
+		Expect(diff.Changes).To(Equal([]FieldChange{
+			{Field: FieldRelease, From: "release-a", To: "release-b"},
+			{Field: FieldSourceRevision, From: "abc123", To: "def456"},
+			{Field: FieldSnapshot, From: "snapshot-a", To: "snapshot-b"},
+			{Field: FieldImageTags, From: "1.0", To: "1.1,latest"},
+			{Field: FieldAdvisory, From: "", To: "https://access.redhat.com/errata/release-b"},
+		}))
+		Expect(diff.CompareURL).To(HaveValue(Equal("https://github.com/org/my-app/compare/abc123...def456")))
+		Expect(diff.String()).To(ContainSubstring("~ advisory: <none> -> https://access.redhat.com/errata/release-b"))
+	})
+
+	It("has no compare URL across repositories", func() {
+		to.SourceRevision = ptr.To("def456")
+		to.SourceURL = ptr.To("https://github.com/org/other-app")
+		Expect(DiffPaths("a", from, "b", to).CompareURL).To(BeNil())
+	})
+})
+
+var _ = DescribeTable("GitCompareURL",
+	func(sourceURL, expected string, ok bool) {
+		compareURL, found := GitCompareURL(sourceURL, "abc123", "def456")
+		Expect(found).To(Equal(ok))
+		Expect(compareURL).To(Equal(expected))
+	},
+	Entry("github", "https://github.com/org/repo.git", "https://github.com/org/repo/compare/abc123...def456", true),
+	Entry("gitlab", "git@gitlab.cee.redhat.com:org/repo", "https://gitlab.cee.redhat.com/org/repo/-/compare/abc123...def456", true),
+	Entry("other host", "https://pagure.io/org/repo", "", false),
+)
@@ -0,0 +1,115 @@
+package metadata
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// Field identifies one Path field. Values match the JSON field names.
+type Field string
+
+const (
+	FieldReleasePlanAdmission Field = "releasePlanAdmission"
+	FieldReleasePlan          Field = "releasePlan"
+	FieldRelease              Field = "release"
+	FieldApplication          Field = "application"
+	FieldSourceRevision       Field = "sourceRevision"
+	FieldSourceURL            Field = "sourceURL"
+	FieldSnapshot             Field = "snapshot"
+	FieldComponentName        Field = "componentName"
+	FieldImageTags            Field = "imageTags"
+	FieldAdvisory             Field = "advisory"
+)
+
+// AllFields lists every field that can be required for a path to be complete
+var AllFields = []Field{
+	FieldReleasePlanAdmission,
+	FieldReleasePlan,
+	FieldRelease,
+	FieldApplication,
+	FieldSourceRevision,
+	FieldSourceURL,
+	FieldSnapshot,
+	FieldComponentName,
+	FieldImageTags,
+	FieldAdvisory,
+}
+
+// DefaultRequiredFields are the fields a path needs to be complete by default.
+// Not every release produces an advisory, so the advisory is optional.
+var DefaultRequiredFields = lo.Without(AllFields, FieldAdvisory)
+
+// ParseFields validates field names
+func ParseFields(names []string) ([]Field, error) {
+	fields := []Field{}
+	for _, name := range names {
+		field := Field(strings.TrimSpace(name))
+		if !lo.Contains(AllFields, field) {
+			return nil, fmt.Errorf("unknown field %q, valid fields: %s", name, strings.Join(lo.Map(AllFields, func(f Field, _ int) string { return string(f) }), ", "))
+		}
+		fields = append(fields, field)
+	}
+	return lo.Uniq(fields), nil
+}
+
+func (p Path) isSet(field Field) bool {
+	switch field {
+	case FieldReleasePlanAdmission:
+		return p.ReleasePlanAdmission != nil
+	case FieldReleasePlan:
+		return p.ReleasePlan != nil
+	case FieldRelease:
+		return p.Release != nil
+	case FieldApplication:
+		return p.Application != nil
+	case FieldSourceRevision:
+		return p.SourceRevision != nil
+	case FieldSourceURL:
+		return p.SourceURL != nil
+	case FieldSnapshot:
+		return p.Snapshot != nil
+	case FieldComponentName:
+		return p.ComponentName != nil
+	case FieldImageTags:
+		return len(p.ImageTags) != 0
+	case FieldAdvisory:
+		return p.Advisory != nil
+	}
+	return false
+}
+
+// MissingFields returns the required fields not set in the path
+func (p Path) MissingFields(required []Field) []Field {
+	return lo.Filter(required, func(field Field, _ int) bool {
+		return !p.isSet(field)
+	})
+}
+
+// value returns the field as a string, empty when unset
+func (p Path) value(field Field) string {
+	switch field {
+	case FieldReleasePlanAdmission:
+		return lo.FromPtr(p.ReleasePlanAdmission)
+	case FieldReleasePlan:
+		return lo.FromPtr(p.ReleasePlan)
+	case FieldRelease:
+		return lo.FromPtr(p.Release)
+	case FieldApplication:
+		return lo.FromPtr(p.Application)
+	case FieldSourceRevision:
+		return lo.FromPtr(p.SourceRevision)
+	case FieldSourceURL:
+		return lo.FromPtr(p.SourceURL)
+	case FieldSnapshot:
+		return lo.FromPtr(p.Snapshot)
+	case FieldComponentName:
+		return lo.FromPtr(p.ComponentName)
+	case FieldImageTags:
+		return strings.Join(p.ImageTags, ",")
+	case FieldAdvisory:
+		return lo.FromPtr(p.Advisory)
+	}
+	return ""
+}
@@ -8,7 +8,6 @@ import (
 	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
 	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
 	"github.com/samber/lo"
-	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/eguzki/konfluxctl/internal/utils"
@@ -23,22 +22,49 @@ type ReleaseArtifacts struct {
 	Advisory ReleaseAdvisory `json:"advisory"`
 }
 
-type ReleaseElement konfluxapi.Release
+type ReleaseElement struct {
+	konfluxapi.Release
+	// components are the names of the components the ReleasePlanAdmission maps to the image repository
+	components []string
+}
 
 func (r *ReleaseElement) String() string {
 	return fmt.Sprintf("%s: %s", "Release", r.Name)
 }
 
+func (r *ReleaseElement) ID() string {
+	return objectID("Release", r.Namespace, r.Name)
+}
+
 func (r *ReleaseElement) Visit(path *Path) {
 	path.Release = &r.Name
-	path.Advisory = ptr.To("<unknown>")
+	path.ReleaseCompletionTime = r.Status.CompletionTime
+	path.Advisory = releaseAdvisory(&r.Release)
+}
 
-	if r.Status.Artifacts != nil {
-		var artifacts ReleaseArtifacts
-		if err := json.Unmarshal(r.Status.Artifacts.Raw, &artifacts); err == nil {
-			path.Advisory = &artifacts.Advisory.URL
-		}
+// releaseArtifacts parses the release status.artifacts, nil when unset or not parseable
+func releaseArtifacts(release *konfluxapi.Release) *ReleaseArtifacts {
+	if release.Status.Artifacts == nil {
+		return nil
+	}
+
+	var artifacts ReleaseArtifacts
+	if err := json.Unmarshal(release.Status.Artifacts.Raw, &artifacts); err != nil {
+		return nil
+	}
+
+	return &artifacts
+}
+
+// releaseAdvisory returns the advisory URL of the release.
+// Nil when unknown, not every release produces an advisory.
+func releaseAdvisory(release *konfluxapi.Release) *string {
+	artifacts := releaseArtifacts(release)
+	if artifacts == nil || artifacts.Advisory.URL == "" {
+		return nil
 	}
+
+	return &artifacts.Advisory.URL
 }
 
 func (r *ReleaseElement) Children(ctx context.Context, k8sClient client.Client, imageURL *utils.ImageURL) ([]Element, error) {
@@ -51,20 +77,50 @@ func (r *ReleaseElement) Children(ctx context.Context, k8sClient client.Client,
 		return nil, err
 	}
 
-	component, ok := lo.Find(snapshot.Spec.Components, func(comp applicationapi.SnapshotComponent) bool {
-		containerImageURL, err := utils.ParseImageURL(comp.ContainerImage)
-		if err != nil {
-			return false
-		}
-		return containerImageURL.Digest() == imageURL.Digest()
-	})
-
+	component, platform, ok := findComponent(ctx, snapshot.Spec.Components, imageURL, r.components)
 	if !ok {
+		traceFilter(ctx, objectID("Snapshot", snapshot.Namespace, snapshot.Name), fmt.Sprintf("Snapshot %s/%s", snapshot.Namespace, snapshot.Name),
+			fmt.Sprintf("no component with image digest %s (%d components)", imageURL.Digest(), len(snapshot.Spec.Components)))
 		return nil, nil
 	}
 
 	return []Element{&SnapshotElement{
 		rawSnapshot: snapshot,
-		component:   &component,
+		component:   component,
+		platform:    platform,
 	}}, nil
 }
+
+// findComponent returns the component built as the image. Components are looked up by digest first.
+// Then, the digests of multi-arch images are matched with the digests of their platform manifests,
+// and the platform of the image is returned. Each match fetches an image index from the registry:
+// only the named platform candidates are matched, every component when nil.
+func findComponent(ctx context.Context, components []applicationapi.SnapshotComponent, imageURL *utils.ImageURL, platformCandidates []string) (*applicationapi.SnapshotComponent, string, bool) {
+	componentImageURLs := lo.Map(components, func(comp applicationapi.SnapshotComponent, _ int) *utils.ImageURL {
+		containerImageURL, err := utils.ParseImageURL(comp.ContainerImage)
+		if err != nil {
+			return nil
+		}
+		return containerImageURL
+	})
+
+	for idx, containerImageURL := range componentImageURLs {
+		if containerImageURL != nil && containerImageURL.Digest() == imageURL.Digest() {
+			return &components[idx], "", true
+		}
+	}
+
+	for idx, containerImageURL := range componentImageURLs {
+		if containerImageURL == nil {
+			continue
+		}
+		if platformCandidates != nil && !lo.Contains(platformCandidates, components[idx].Name) {
+			continue
+		}
+		if platform, ok := matchPlatform(ctx, containerImageURL, imageURL); ok {
+			return &components[idx], platform, true
+		}
+	}
+
+	return nil, "", false
+}
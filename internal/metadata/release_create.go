@@ -0,0 +1,92 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NewRelease returns a Release of the snapshot with the release plan, not created yet.
+// The snapshot and the release plan must exist, the release plan must be matched to a ReleasePlanAdmission
+// and both must belong to the same application. The data, YAML or JSON, is passed to the managed pipeline.
+func NewRelease(ctx context.Context, k8sClient client.Client, namespace, snapshotName, releasePlanName string, data []byte) (*konfluxapi.Release, error) {
+	snapshot := &applicationapi.Snapshot{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: snapshotName}, snapshot); err != nil {
+		return nil, fmt.Errorf("getting snapshot: %w", err)
+	}
+
+	releasePlan := &konfluxapi.ReleasePlan{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: releasePlanName}, releasePlan); err != nil {
+		return nil, fmt.Errorf("getting release plan: %w", err)
+	}
+
+	matched := string(konfluxapi.MatchedConditionType)
+	if !meta.IsStatusConditionTrue(releasePlan.Status.Conditions, matched) {
+		return nil, fmt.Errorf("release plan %s is not matched to a ReleasePlanAdmission: %s",
+			releasePlanName, conditionNotTrueReason(releasePlan.Status.Conditions, matched))
+	}
+
+	if releasePlan.Spec.Application != snapshot.Spec.Application {
+		return nil, fmt.Errorf("snapshot %s belongs to application %s, release plan %s releases application %s",
+			snapshotName, snapshot.Spec.Application, releasePlanName, releasePlan.Spec.Application)
+	}
+
+	release := &konfluxapi.Release{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: konfluxapi.GroupVersion.String(),
+			Kind:       "Release",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			// generated client side, dry runs print the name of the release to be created
+			Name:      fmt.Sprintf("%s-%s", releasePlanName, utilrand.String(5)),
+			Namespace: namespace,
+		},
+		Spec: konfluxapi.ReleaseSpec{
+			Snapshot:    snapshotName,
+			ReleasePlan: releasePlanName,
+		},
+	}
+
+	if len(data) > 0 {
+		jsonData, err := yaml.YAMLToJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing release data: %w", err)
+		}
+		release.Spec.Data = &k8sruntime.RawExtension{Raw: jsonData}
+	}
+
+	return release, nil
+}
+
+// ReleaseManifest is a Release to be created, as dry runs print it.
+// The fields populated by the API server, the status and the creation timestamp, are left out.
+type ReleaseManifest struct {
+	*konfluxapi.Release
+}
+
+// Names is the name of the Release, printed with -o name
+func (m ReleaseManifest) Names() []string {
+	return []string{releaseServiceName("release", m.Name)}
+}
+
+func (m ReleaseManifest) MarshalJSON() ([]byte, error) {
+	manifest, err := k8sruntime.DefaultUnstructuredConverter.ToUnstructured(m.Release)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(manifest, "status")
+	unstructured.RemoveNestedField(manifest, "metadata", "creationTimestamp")
+
+	return json.Marshal(manifest)
+}
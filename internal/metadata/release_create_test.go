@@ -0,0 +1,96 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/eguzki/konfluxctl/internal/output"
+)
+
+var _ = Describe("NewRelease", func() {
+	var (
+		ctx       context.Context
+		k8sClient client.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		otherSnapshot := testSnapshot("other-snapshot", testDigest)
+		otherSnapshot.Spec.Application = "other-application"
+		k8sClient = testClient(
+			testReleasePlan("my-rp", true),
+			testReleasePlan("unmatched-rp", false),
+			testSnapshot("snapshot-a", testDigest),
+			otherSnapshot,
+		)
+	})
+
+	It("returns the release of the snapshot with the plan", func() {
+		release, err := NewRelease(ctx, k8sClient, testTenantNamespace, "snapshot-a", "my-rp", []byte("releaseNotes:\n  type: RHBA\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(release.Name).To(HavePrefix("my-rp-"))
+		Expect(release.Namespace).To(Equal(testTenantNamespace))
+		Expect(release.Spec.Snapshot).To(Equal("snapshot-a"))
+		Expect(release.Spec.ReleasePlan).To(Equal("my-rp"))
+		Expect(release.Spec.Data.Raw).To(MatchJSON(`{"releaseNotes":{"type":"RHBA"}}`))
+
+		Expect(k8sClient.Create(ctx, release)).To(Succeed())
+	})
+
+	DescribeTable("validates the snapshot and the plan",
+		func(snapshot, releasePlan, expectedErr string) {
+			_, err := NewRelease(ctx, k8sClient, testTenantNamespace, snapshot, releasePlan, nil)
+			Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+		},
+		Entry("unknown snapshot", "unknown", "my-rp", "getting snapshot"),
+		Entry("unknown plan", "snapshot-a", "unknown", "getting release plan"),
+		Entry("unmatched plan", "snapshot-a", "unmatched-rp", "is not matched"),
+		Entry("other application", "other-snapshot", "my-rp", "belongs to application other-application"),
+	)
+
+	It("leaves the server populated fields out of the manifest", func() {
+		release, err := NewRelease(ctx, k8sClient, testTenantNamespace, "snapshot-a", "my-rp", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		manifest, err := yaml.Marshal(ReleaseManifest{Release: release})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(manifest)).To(Equal(fmt.Sprintf(`apiVersion: appstudio.redhat.com/v1alpha1
+kind: Release
+metadata:
+  name: %s
+  namespace: my-tenant
+spec:
+  releasePlan: my-rp
+  snapshot: snapshot-a
+`, release.Name)))
+	})
+
+	It("prints the release name like the other -o name outputs", func() {
+		release, err := NewRelease(ctx, k8sClient, testTenantNamespace, "snapshot-a", "my-rp", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		flags := output.NewFlags(output.FormatYAML, output.FormatName)
+		cmd := &cobra.Command{}
+		flags.AddFlags(cmd)
+		Expect(cmd.Flags().Set("output-format", "name")).To(Succeed())
+		printer, err := flags.Printer()
+		Expect(err).ToNot(HaveOccurred())
+
+		var out bytes.Buffer
+		Expect(printer.Print(&out, ReleaseManifest{Release: release})).To(Succeed())
+		Expect(out.String()).To(Equal("release.appstudio.redhat.com/" + release.Name + "\n"))
+		Expect(out.String()).To(HavePrefix(ReleaseSummaryList{{Name: release.Name}}.Names()[0]))
+	})
+
+	It("rejects invalid data", func() {
+		_, err := NewRelease(ctx, k8sClient, testTenantNamespace, "snapshot-a", "my-rp", []byte("a: [b"))
+		Expect(err).To(MatchError(ContainSubstring("parsing release data")))
+	})
+})
@@ -0,0 +1,175 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	"github.com/samber/lo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReleaseDetails is the full breakdown of one release
+type ReleaseDetails struct {
+	ReleaseSummary
+
+	Author               string `json:"author,omitempty"`
+	Automated            bool   `json:"automated"`
+	ReleasePlanAdmission string `json:"releasePlanAdmission,omitempty"`
+	// Artifacts are the parsed status.artifacts, nil when the release has none
+	Artifacts    *ReleaseArtifacts    `json:"artifacts,omitempty"`
+	Components   []ComponentSummary   `json:"components"`
+	Conditions   []metav1.Condition   `json:"conditions"`
+	PipelineRuns []ReleasePipelineRun `json:"pipelineRuns"`
+}
+
+// ReleasePipelineRun references a pipeline run executed by the release
+type ReleasePipelineRun struct {
+	// Stage is tenant, managed, final, tenantCollectors or managedCollectors
+	Stage          string       `json:"stage"`
+	PipelineRun    string       `json:"pipelineRun"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// String renders the release like kubectl describe, with the artifacts parsed
+func (d *ReleaseDetails) String() string {
+	var b strings.Builder
+
+	duration := "<none>"
+	if d.Duration != nil {
+		duration = d.Duration.Round(time.Second).String()
+	}
+
+	author := lo.Ternary(d.Author != "", d.Author, "<none>")
+	if d.Automated {
+		author += " (automated)"
+	}
+
+	fmt.Fprintf(&b, `Release: %s/%s
+Status: %s
+Application: %s
+ReleasePlan: %s
+ReleasePlanAdmission: %s
+Target: %s
+Author: %s
+Created: %s
+Started: %s
+Completed: %s
+Duration: %s
+`,
+		d.Namespace, d.Name,
+		d.Status,
+		lo.Ternary(d.Application != "", d.Application, "<none>"),
+		d.ReleasePlan,
+		lo.Ternary(d.ReleasePlanAdmission != "", d.ReleasePlanAdmission, "<none>"),
+		lo.Ternary(d.Target != "", d.Target, "<none>"),
+		author,
+		completionTimeString(&d.CreationTime),
+		completionTimeString(d.StartTime),
+		completionTimeString(d.CompletionTime),
+		duration,
+	)
+
+	b.WriteString("Advisory:")
+	if d.Artifacts == nil || d.Artifacts.Advisory.URL == "" {
+		b.WriteString(" <none>\n")
+	} else {
+		fmt.Fprintf(&b, "\n  URL: %s\n", d.Artifacts.Advisory.URL)
+		if d.Artifacts.Advisory.InternalURL != "" {
+			fmt.Fprintf(&b, "  Internal URL: %s\n", d.Artifacts.Advisory.InternalURL)
+		}
+	}
+
+	fmt.Fprintf(&b, "Snapshot: %s\n", d.Snapshot)
+	writeComponents(&b, d.Components)
+
+	b.WriteString("Conditions:\n")
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "  TYPE\tSTATUS\tREASON\tLAST TRANSITION\tMESSAGE")
+	for _, condition := range d.Conditions {
+		_, _ = fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", condition.Type, condition.Status, condition.Reason,
+			completionTimeString(&condition.LastTransitionTime), condition.Message)
+	}
+	_ = w.Flush()
+
+	b.WriteString("Pipeline Runs:")
+	if len(d.PipelineRuns) == 0 {
+		b.WriteString(" <none>")
+	}
+	for _, run := range d.PipelineRuns {
+		fmt.Fprintf(&b, "\n  %s: %s (started: %s, completed: %s)", run.Stage, run.PipelineRun,
+			completionTimeString(run.StartTime), completionTimeString(run.CompletionTime))
+	}
+
+	return b.String()
+}
+
+// GetRelease returns the breakdown of the release: the plan and the ReleasePlanAdmission it matched,
+// the snapshot components, the parsed artifacts, the conditions and the pipeline runs.
+// Missing plan or snapshot are not an error, the related fields are left empty.
+func GetRelease(ctx context.Context, k8sClient client.Client, namespace, name string) (*ReleaseDetails, error) {
+	release := &konfluxapi.Release{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, release); err != nil {
+		return nil, err
+	}
+
+	summary, releasePlan, err := summarizeRelease(ctx, k8sClient, release)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &ReleaseDetails{
+		ReleaseSummary:       summary,
+		Author:               release.Status.Attribution.Author,
+		Automated:            release.Status.Automated,
+		Artifacts:            releaseArtifacts(release),
+		Conditions:           release.Status.Conditions,
+		PipelineRuns:         releasePipelineRuns(release),
+		ReleasePlanAdmission: releasePlan.Status.ReleasePlanAdmission.Name,
+	}
+
+	snapshot := &applicationapi.Snapshot{}
+	err = k8sClient.Get(ctx, client.ObjectKey{Namespace: release.Namespace, Name: release.Spec.Snapshot}, snapshot)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	details.Components = componentSummaries(snapshot)
+
+	return details, nil
+}
+
+// releasePipelineRuns returns the pipeline runs in execution order, skipping the stages not run
+func releasePipelineRuns(release *konfluxapi.Release) []ReleasePipelineRun {
+	stages := []struct {
+		name string
+		info konfluxapi.PipelineInfo
+	}{
+		{"tenantCollectors", release.Status.CollectorsProcessing.TenantCollectorsProcessing},
+		{"managedCollectors", release.Status.CollectorsProcessing.ManagedCollectorsProcessing},
+		{"tenant", release.Status.TenantProcessing},
+		{"managed", release.Status.ManagedProcessing},
+		{"final", release.Status.FinalProcessing},
+	}
+
+	runs := []ReleasePipelineRun{}
+	for _, stage := range stages {
+		if stage.info.PipelineRun == "" {
+			continue
+		}
+		runs = append(runs, ReleasePipelineRun{
+			Stage:          stage.name,
+			PipelineRun:    stage.info.PipelineRun,
+			StartTime:      stage.info.StartTime,
+			CompletionTime: stage.info.CompletionTime,
+		})
+	}
+
+	return runs
+}
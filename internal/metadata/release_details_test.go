@@ -0,0 +1,99 @@
+package metadata
+
+import (
+	"context"
+	"time"
+
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("GetRelease", func() {
+	var (
+		ctx context.Context
+		t0  time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		t0 = time.Date(2025, time.May, 1, 10, 0, 0, 0, time.UTC)
+	})
+
+	It("returns the breakdown of the release", func() {
+		releasePlan := testReleasePlan("my-rp", true)
+		releasePlan.Status.ReleasePlanAdmission.Name = testManagedNamespace + "/my-rpa"
+
+		release := testRelease("release-ga", "my-rp", "snapshot-a", true, t0.Add(time.Hour))
+		release.Status.StartTime = &metav1.Time{Time: t0}
+		release.Status.Attribution.Author = "alice"
+		release.Status.ManagedProcessing.PipelineRun = testManagedNamespace + "/managed-abcde"
+		release.Status.TenantProcessing.PipelineRun = testTenantNamespace + "/tenant-abcde"
+
+		k8sClient := testClient(releasePlan, release, testSnapshot("snapshot-a", testDigest))
+
+		details, err := GetRelease(ctx, k8sClient, testTenantNamespace, "release-ga")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(details.Status).To(Equal(ReleaseStatusReleased))
+		Expect(details.Application).To(Equal("my-application"))
+		Expect(details.ReleasePlanAdmission).To(Equal(testManagedNamespace + "/my-rpa"))
+		Expect(details.Author).To(Equal("alice"))
+		Expect(details.Duration.Duration).To(Equal(time.Hour))
+		Expect(details.Artifacts.Advisory.URL).To(Equal("https://access.redhat.com/errata/release-ga"))
+		Expect(details.Components).To(Equal([]ComponentSummary{{
+			Name:           "my-component",
+			Image:          "quay.io/redhat-user-workloads/my-tenant/my-component@" + testDigest,
+			SourceURL:      "https://github.com/org/my-app",
+			SourceRevision: "abc123",
+		}}))
+		Expect(details.Conditions).To(HaveLen(1))
+		Expect(details.PipelineRuns).To(HaveExactElements(
+			HaveField("Stage", "tenant"),
+			HaveField("Stage", "managed"),
+		))
+
+		Expect(details.String()).To(ContainSubstring("managed: " + testManagedNamespace + "/managed-abcde"))
+	})
+
+	It("reads the release plan once", func() {
+		releasePlan := testReleasePlan("my-rp", true)
+		releasePlan.Status.ReleasePlanAdmission.Name = testManagedNamespace + "/my-rpa"
+
+		planGets := 0
+		k8sClient := fake.NewClientBuilder().WithScheme(testScheme()).
+			WithObjects(releasePlan, testRelease("release-ga", "my-rp", "snapshot-a", true, t0)).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if _, ok := obj.(*konfluxapi.ReleasePlan); ok {
+						planGets++
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			}).Build()
+
+		details, err := GetRelease(ctx, k8sClient, testTenantNamespace, "release-ga")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(details.ReleasePlanAdmission).To(Equal(testManagedNamespace + "/my-rpa"))
+		Expect(planGets).To(Equal(1))
+	})
+
+	It("tolerates missing plan and snapshot", func() {
+		k8sClient := testClient(testRelease("release-ga", "my-rp", "snapshot-a", false, t0))
+
+		details, err := GetRelease(ctx, k8sClient, testTenantNamespace, "release-ga")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(details.Status).To(Equal(ReleaseStatusFailed))
+		Expect(details.Application).To(BeEmpty())
+		Expect(details.Components).To(BeEmpty())
+	})
+
+	It("fails when the release does not exist", func() {
+		_, err := GetRelease(ctx, testClient(), testTenantNamespace, "unknown")
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
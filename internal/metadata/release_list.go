@@ -0,0 +1,189 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	"github.com/samber/lo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/eguzki/konfluxctl/internal/output"
+)
+
+// ReleaseStatus summarizes the Released condition of a release
+type ReleaseStatus string
+
+const (
+	ReleaseStatusReleased    ReleaseStatus = "released"
+	ReleaseStatusFailed      ReleaseStatus = "failed"
+	ReleaseStatusProgressing ReleaseStatus = "progressing"
+)
+
+// AllReleaseStatuses lists the valid release statuses
+var AllReleaseStatuses = []ReleaseStatus{ReleaseStatusReleased, ReleaseStatusFailed, ReleaseStatusProgressing}
+
+// ParseReleaseStatus validates release status names
+func ParseReleaseStatus(name string) (ReleaseStatus, error) {
+	status := ReleaseStatus(name)
+	if !lo.Contains(AllReleaseStatuses, status) {
+		return "", fmt.Errorf("unknown release status %q, valid statuses: %v", name, AllReleaseStatuses)
+	}
+	return status, nil
+}
+
+// ReleaseSummary is one row of the release list
+type ReleaseSummary struct {
+	Name           string           `json:"name"`
+	Namespace      string           `json:"namespace"`
+	Application    string           `json:"application"`
+	ReleasePlan    string           `json:"releasePlan"`
+	Snapshot       string           `json:"snapshot"`
+	Target         string           `json:"target"`
+	Status         ReleaseStatus    `json:"status"`
+	Advisory       *string          `json:"advisory"`
+	CreationTime   metav1.Time      `json:"creationTime"`
+	StartTime      *metav1.Time     `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time     `json:"completionTime,omitempty"`
+	Duration       *metav1.Duration `json:"duration,omitempty"`
+}
+
+// ReleaseSummaryList is the result of ListReleases, most recently created first
+type ReleaseSummaryList []ReleaseSummary
+
+var releaseColumns = []output.Column{
+	{Name: "NAMESPACE"}, {Name: "NAME"}, {Name: "APPLICATION"}, {Name: "RELEASE PLAN"}, {Name: "SNAPSHOT"},
+	{Name: "TARGET"}, {Name: "STATUS"}, {Name: "CREATED"}, {Name: "DURATION"}, {Name: "ADVISORY"},
+	{Name: "STARTED", Wide: true}, {Name: "COMPLETED", Wide: true},
+}
+
+func (l ReleaseSummaryList) Table() *output.Table {
+	return &output.Table{
+		Columns: releaseColumns,
+		Rows: lo.Map(l, func(r ReleaseSummary, _ int) []string {
+			return []string{r.Namespace, r.Name, cell(r.Application), r.ReleasePlan, r.Snapshot,
+				cell(r.Target), string(r.Status), timeCell(&r.CreationTime), durationCell(r.Duration),
+				lo.FromPtrOr(r.Advisory, "-"), timeCell(r.StartTime), timeCell(r.CompletionTime)}
+		}),
+	}
+}
+
+func (l ReleaseSummaryList) Names() []string {
+	return lo.Map(l, func(r ReleaseSummary, _ int) string { return releaseServiceName("release", r.Name) })
+}
+
+// Table is the one row table of the release, see ReleaseSummaryList.Table
+func (r *ReleaseSummary) Table() *output.Table {
+	return ReleaseSummaryList{*r}.Table()
+}
+
+func (r *ReleaseSummary) Names() []string {
+	return ReleaseSummaryList{*r}.Names()
+}
+
+// ReleaseFilter selects releases. Empty fields match every release.
+type ReleaseFilter struct {
+	Application string
+	ReleasePlan string
+	Status      ReleaseStatus
+	// Since and Until bound the release creation time
+	Since time.Time
+	Until time.Time
+}
+
+func (f ReleaseFilter) matches(summary ReleaseSummary) bool {
+	switch {
+	case f.Application != "" && summary.Application != f.Application:
+		return false
+	case f.ReleasePlan != "" && summary.ReleasePlan != f.ReleasePlan:
+		return false
+	case f.Status != "" && summary.Status != f.Status:
+		return false
+	case !f.Since.IsZero() && summary.CreationTime.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && summary.CreationTime.Time.After(f.Until):
+		return false
+	}
+	return true
+}
+
+// ListReleases returns the releases matching the filter, most recently created first.
+// Releases are looked up in the given namespaces, or across all namespaces the user can read when none is given.
+func ListReleases(ctx context.Context, k8sClient client.Client, filter ReleaseFilter, namespaces []string) (ReleaseSummaryList, error) {
+	lists, err := listInNamespaces(ctx, k8sClient, namespaces, func() *konfluxapi.ReleaseList {
+		return &konfluxapi.ReleaseList{}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := ReleaseSummaryList{}
+	for _, list := range lists {
+		for idx := range list.Items {
+			summary, _, err := summarizeRelease(ctx, k8sClient, &list.Items[idx])
+			if err != nil {
+				return nil, err
+			}
+			if filter.matches(summary) {
+				result = append(result, summary)
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if !result[i].CreationTime.Equal(&result[j].CreationTime) {
+			return result[j].CreationTime.Before(&result[i].CreationTime)
+		}
+		return result[i].Namespace+"/"+result[i].Name < result[j].Namespace+"/"+result[j].Name
+	})
+
+	return result, nil
+}
+
+// summarizeRelease also returns the release plan it read, empty when the plan does not exist
+func summarizeRelease(ctx context.Context, k8sClient client.Client, release *konfluxapi.Release) (ReleaseSummary, *konfluxapi.ReleasePlan, error) {
+	summary := ReleaseSummary{
+		Name:           release.Name,
+		Namespace:      release.Namespace,
+		ReleasePlan:    release.Spec.ReleasePlan,
+		Snapshot:       release.Spec.Snapshot,
+		Target:         release.Status.Target,
+		Status:         releaseStatus(release),
+		Advisory:       releaseAdvisory(release),
+		CreationTime:   release.CreationTimestamp,
+		StartTime:      release.Status.StartTime,
+		CompletionTime: release.Status.CompletionTime,
+	}
+
+	if release.Status.StartTime != nil && release.Status.CompletionTime != nil {
+		summary.Duration = &metav1.Duration{Duration: release.Status.CompletionTime.Sub(release.Status.StartTime.Time)}
+	}
+
+	// releases do not reference the application, their release plan does
+	releasePlan := &konfluxapi.ReleasePlan{}
+	err := k8sClient.Get(ctx, client.ObjectKey{Namespace: release.Namespace, Name: release.Spec.ReleasePlan}, releasePlan)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return summary, nil, err
+	}
+	summary.Application = releasePlan.Spec.Application
+	if summary.Target == "" {
+		summary.Target = releasePlan.Spec.Target
+	}
+
+	return summary, releasePlan, nil
+}
+
+func releaseStatus(release *konfluxapi.Release) ReleaseStatus {
+	switch {
+	case release.IsReleased():
+		return ReleaseStatusReleased
+	case release.IsFailed():
+		return ReleaseStatusFailed
+	default:
+		return ReleaseStatusProgressing
+	}
+}
@@ -0,0 +1,93 @@
+package metadata
+
+import (
+	"context"
+	"time"
+
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("ListReleases", func() {
+	var (
+		ctx       context.Context
+		t0        time.Time
+		k8sClient client.Client
+	)
+
+	releaseNames := func(releases ReleaseSummaryList) []string {
+		return lo.Map(releases, func(r ReleaseSummary, _ int) string { return r.Name })
+	}
+
+	createdAt := func(release *konfluxapi.Release, created time.Time) *konfluxapi.Release {
+		release.CreationTimestamp = metav1.Time{Time: created}
+		release.Status.StartTime = &metav1.Time{Time: created}
+		return release
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		t0 = time.Date(2025, time.May, 1, 10, 0, 0, 0, time.UTC)
+
+		otherPlan := testReleasePlan("other-rp", true)
+		otherPlan.Spec.Application = "other-application"
+
+		progressing := createdAt(testRelease("release-progressing", "my-rp", "snapshot-c", true, t0), t0.Add(3*time.Hour))
+		progressing.Status.Conditions = nil
+		progressing.Status.CompletionTime = nil
+
+		k8sClient = testClient(
+			testReleasePlan("my-rp", true),
+			otherPlan,
+			createdAt(testRelease("release-ga", "my-rp", "snapshot-a", true, t0.Add(30*time.Minute)), t0),
+			createdAt(testRelease("release-failed", "my-rp", "snapshot-b", false, t0.Add(time.Hour+10*time.Minute)), t0.Add(time.Hour)),
+			createdAt(testRelease("release-other", "other-rp", "snapshot-o", true, t0.Add(2*time.Hour+5*time.Minute)), t0.Add(2*time.Hour)),
+			progressing,
+		)
+	})
+
+	It("returns every release, most recently created first", func() {
+		releases, err := ListReleases(ctx, k8sClient, ReleaseFilter{}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(releaseNames(releases)).To(Equal([]string{"release-progressing", "release-other", "release-failed", "release-ga"}))
+
+		Expect(releases[0].Status).To(Equal(ReleaseStatusProgressing))
+		Expect(releases[0].Duration).To(BeNil())
+
+		ga := releases[3]
+		Expect(ga.Status).To(Equal(ReleaseStatusReleased))
+		Expect(ga.Application).To(Equal("my-application"))
+		Expect(ga.Snapshot).To(Equal("snapshot-a"))
+		Expect(ga.Target).To(Equal(testManagedNamespace))
+		Expect(ga.Advisory).To(HaveValue(Equal("https://access.redhat.com/errata/release-ga")))
+		Expect(ga.Duration.Duration).To(Equal(30 * time.Minute))
+	})
+
+	DescribeTable("filters releases",
+		func(filter func() ReleaseFilter, expected []string) {
+			releases, err := ListReleases(ctx, k8sClient, filter(), []string{testTenantNamespace})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(releaseNames(releases)).To(Equal(expected))
+		},
+		Entry("by application", func() ReleaseFilter { return ReleaseFilter{Application: "other-application"} },
+			[]string{"release-other"}),
+		Entry("by release plan", func() ReleaseFilter { return ReleaseFilter{ReleasePlan: "my-rp"} },
+			[]string{"release-progressing", "release-failed", "release-ga"}),
+		Entry("by status", func() ReleaseFilter { return ReleaseFilter{Status: ReleaseStatusFailed} },
+			[]string{"release-failed"}),
+		Entry("by time range", func() ReleaseFilter {
+			return ReleaseFilter{Since: t0.Add(30 * time.Minute), Until: t0.Add(2 * time.Hour)}
+		}, []string{"release-other", "release-failed"}),
+	)
+})
+
+var _ = Describe("ParseReleaseStatus", func() {
+	It("rejects unknown statuses", func() {
+		_, err := ParseReleaseStatus("done")
+		Expect(err).To(MatchError(ContainSubstring("unknown release status")))
+	})
+})
@@ -7,17 +7,43 @@ import (
 	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
 	"github.com/samber/lo"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/eguzki/konfluxctl/internal/utils"
 )
 
-type ReleasePlanElement konfluxapi.ReleasePlan
+type ReleasePlanElement struct {
+	konfluxapi.ReleasePlan
+	// components are the names of the components the ReleasePlanAdmission maps to the image repository
+	components []string
+}
+
+// conditionNotTrueReason describes why the condition is not true
+func conditionNotTrueReason(conditions []metav1.Condition, conditionType string) string {
+	condition := meta.FindStatusCondition(conditions, conditionType)
+	if condition == nil {
+		return fmt.Sprintf("condition %s not found", conditionType)
+	}
+
+	reason := fmt.Sprintf("condition %s is %s", conditionType, condition.Status)
+	if condition.Reason != "" {
+		reason = fmt.Sprintf("%s (reason: %s)", reason, condition.Reason)
+	}
+	if condition.Message != "" {
+		reason = fmt.Sprintf("%s: %s", reason, condition.Message)
+	}
+	return reason
+}
 
 func (r *ReleasePlanElement) String() string {
 	return fmt.Sprintf("%s: %s", "ReleasePlan", r.Name)
 }
 
+func (r *ReleasePlanElement) ID() string {
+	return objectID("ReleasePlan", r.Namespace, r.Name)
+}
+
 func (r *ReleasePlanElement) Visit(path *Path) {
 	path.ReleasePlan = &r.Name
 }
@@ -30,12 +56,20 @@ func (r *ReleasePlanElement) Children(ctx context.Context, k8sClient client.Clie
 	}
 
 	planReleaseList := lo.Filter(releaseList.Items, func(release konfluxapi.Release, _ int) bool {
-		return release.Spec.ReleasePlan == r.Name &&
-			meta.IsStatusConditionTrue(release.Status.Conditions, "Released")
+		return release.Spec.ReleasePlan == r.Name
+	})
+
+	traceNote(ctx, "%d releases in namespace %s, %d created from this plan", len(releaseList.Items), r.Namespace, len(planReleaseList))
+
+	planReleaseList = lo.Filter(planReleaseList, func(release konfluxapi.Release, _ int) bool {
+		released := meta.IsStatusConditionTrue(release.Status.Conditions, "Released")
+		if !released {
+			traceFilter(ctx, objectID("Release", release.Namespace, release.Name), fmt.Sprintf("Release %s", release.Name), conditionNotTrueReason(release.Status.Conditions, "Released"))
+		}
+		return released
 	})
 
 	return lo.Map(planReleaseList, func(e konfluxapi.Release, _ int) Element {
-		tmp := ReleaseElement(e)
-		return &tmp
+		return &ReleaseElement{Release: e, components: r.components}
 	}), nil
 }
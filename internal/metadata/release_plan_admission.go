@@ -14,6 +14,9 @@ import (
 	"github.com/eguzki/konfluxctl/internal/utils"
 )
 
+// DefaultReleasePlanAdmissionNamespace is the managed namespace of the public Konflux instance
+const DefaultReleasePlanAdmissionNamespace = "rhtap-releng-tenant"
+
 type Repository struct {
 	Url  string   `json:"url"`
 	Tags []string `json:"tags"`
@@ -35,18 +38,28 @@ type ReleasePlanAdmissionData struct {
 type ReleasePlanAdmissionElement struct {
 	rawRPA konfluxapi.ReleasePlanAdmission
 	tags   []string
+	// components are the names of the components mapped to the image repository
+	components []string
 }
 
 func (r *ReleasePlanAdmissionElement) String() string {
 	return fmt.Sprintf("%s: %s", "ReleasePlanAdmission", r.rawRPA.Name)
 }
 
+func (r *ReleasePlanAdmissionElement) ID() string {
+	return objectID("ReleasePlanAdmission", r.rawRPA.Namespace, r.rawRPA.Name)
+}
+
 func (r *ReleasePlanAdmissionElement) Visit(path *Path) {
 	path.ReleasePlanAdmission = &r.rawRPA.Name
 	path.ImageTags = r.tags
 }
 
 func (r *ReleasePlanAdmissionElement) Children(ctx context.Context, k8sClient client.Client, imageURL *utils.ImageURL) ([]Element, error) {
+	if len(r.rawRPA.Status.ReleasePlans) == 0 {
+		traceNote(ctx, "no ReleasePlan matched (status.releasePlans is empty)")
+	}
+
 	children := []*konfluxapi.ReleasePlan{}
 	for _, matchedReleasePlan := range r.rawRPA.Status.ReleasePlans {
 		namespacedName := strings.Split(matchedReleasePlan.Name, "/")
@@ -64,43 +77,88 @@ func (r *ReleasePlanAdmissionElement) Children(ctx context.Context, k8sClient cl
 
 	// Filter out those in bad condition
 	validReleasePlans := lo.Filter(children, func(c *konfluxapi.ReleasePlan, _ int) bool {
-		return meta.IsStatusConditionTrue(c.Status.Conditions, string(konfluxapi.MatchedConditionType))
+		matched := meta.IsStatusConditionTrue(c.Status.Conditions, string(konfluxapi.MatchedConditionType))
+		if !matched {
+			traceFilter(ctx, objectID("ReleasePlan", c.Namespace, c.Name), fmt.Sprintf("ReleasePlan %s/%s", c.Namespace, c.Name),
+				conditionNotTrueReason(c.Status.Conditions, string(konfluxapi.MatchedConditionType)))
+		}
+		return matched
 	})
 
 	return lo.Map(validReleasePlans, func(e *konfluxapi.ReleasePlan, _ int) Element {
-		tmp := ReleasePlanElement(*e)
-		return &tmp
+		return &ReleasePlanElement{ReleasePlan: *e, components: r.components}
 	}), nil
 }
 
-func ReleasePlanAdmissionList(ctx context.Context, k8sClient client.Client, imageName string) ([]Element, error) {
-	rpaList := &konfluxapi.ReleasePlanAdmissionList{}
-	err := k8sClient.List(ctx, rpaList, client.InNamespace("rhtap-releng-tenant"))
+// ReleasePlanAdmissionList returns the ReleasePlanAdmissions mapping the image name to some repository.
+// ReleasePlanAdmissions are looked up in the given namespaces. When no namespace is given,
+// they are looked up across all namespaces the user can read.
+func ReleasePlanAdmissionList(ctx context.Context, k8sClient client.Client, imageName string, namespaces []string) ([]Element, error) {
+	rpaList, err := listReleasePlanAdmissions(ctx, k8sClient, namespaces)
 	if err != nil {
 		return nil, err
 	}
 
-	return lo.FilterMap(rpaList.Items, func(rpa konfluxapi.ReleasePlanAdmission, index int) (Element, bool) {
+	if len(namespaces) == 0 {
+		traceNote(ctx, "%d ReleasePlanAdmissions found across all namespaces", len(rpaList))
+	} else {
+		traceNote(ctx, "%d ReleasePlanAdmissions found in namespaces %s", len(rpaList), strings.Join(namespaces, ","))
+	}
+
+	return lo.FilterMap(rpaList, func(rpa konfluxapi.ReleasePlanAdmission, index int) (Element, bool) {
+		candidate := fmt.Sprintf("ReleasePlanAdmission %s/%s", rpa.Namespace, rpa.Name)
+		candidateID := objectID("ReleasePlanAdmission", rpa.Namespace, rpa.Name)
+
+		if rpa.Spec.Data == nil {
+			traceFilter(ctx, candidateID, candidate, "spec.data is empty")
+			return nil, false
+		}
+
 		var data ReleasePlanAdmissionData
 		if err := json.Unmarshal(rpa.Spec.Data.Raw, &data); err != nil {
+			traceFilter(ctx, candidateID, candidate, fmt.Sprintf("spec.data cannot be parsed: %s", err))
 			return nil, false
 		}
 
-		repositories := lo.FlatMap(data.Mappping.Components, func(comp ReleasePlanAdmissionDataComponent, _ int) []Repository {
-			return comp.Repositories
-		})
-
-		repository, ok := lo.Find(repositories, func(repo Repository) bool {
-			return repo.Url == imageName
-		})
+		var (
+			repository *Repository
+			components []string
+		)
+		for _, comp := range data.Mappping.Components {
+			repo, ok := lo.Find(comp.Repositories, func(repo Repository) bool {
+				return repo.Url == imageName
+			})
+			if !ok {
+				continue
+			}
+			if repository == nil {
+				repository = &repo
+			}
+			components = append(components, comp.Name)
+		}
 
-		if !ok {
+		if repository == nil {
+			traceFilter(ctx, candidateID, candidate, fmt.Sprintf("no repository mapping for %s", imageName))
 			return nil, false
 		}
 
 		return &ReleasePlanAdmissionElement{
-			rawRPA: rpa,
-			tags:   repository.Tags,
+			rawRPA:     rpa,
+			tags:       repository.Tags,
+			components: components,
 		}, true
 	}), nil
 }
+
+func listReleasePlanAdmissions(ctx context.Context, k8sClient client.Client, namespaces []string) ([]konfluxapi.ReleasePlanAdmission, error) {
+	lists, err := listInNamespaces(ctx, k8sClient, namespaces, func() *konfluxapi.ReleasePlanAdmissionList {
+		return &konfluxapi.ReleasePlanAdmissionList{}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.FlatMap(lists, func(l *konfluxapi.ReleasePlanAdmissionList, _ int) []konfluxapi.ReleasePlanAdmission {
+		return l.Items
+	}), nil
+}
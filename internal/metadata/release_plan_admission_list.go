@@ -0,0 +1,205 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	tektonutils "github.com/konflux-ci/release-service/tekton/utils"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/eguzki/konfluxctl/internal/output"
+	"github.com/eguzki/konfluxctl/internal/utils"
+)
+
+// ReleasePlanAdmissionSummary is one ReleasePlanAdmission with its component to repository mapping
+type ReleasePlanAdmissionSummary struct {
+	Name         string      `json:"name"`
+	Namespace    string      `json:"namespace"`
+	Origin       string      `json:"origin"`
+	Applications []string    `json:"applications"`
+	Policy       string      `json:"policy"`
+	Pipeline     string      `json:"pipeline,omitempty"`
+	CreationTime metav1.Time `json:"creationTime"`
+	// ReleasePlans are the matched ReleasePlans, as namespace/name
+	ReleasePlans []string                            `json:"releasePlans"`
+	Components   []ReleasePlanAdmissionDataComponent `json:"components"`
+	// DataError is set when spec.data cannot be parsed, components are unknown then
+	DataError string `json:"dataError,omitempty"`
+}
+
+func (r *ReleasePlanAdmissionSummary) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ReleasePlanAdmission: %s/%s\nOrigin: %s\nApplications: %s\nPolicy: %s\nPipeline: %s\nCreated: %s\n",
+		r.Namespace, r.Name, r.Origin, strings.Join(r.Applications, ","), r.Policy,
+		lo.Ternary(r.Pipeline == "", "<none>", r.Pipeline), completionTimeString(&r.CreationTime))
+
+	b.WriteString("ReleasePlans:\n")
+	for _, releasePlan := range r.ReleasePlans {
+		fmt.Fprintf(&b, "  %s\n", releasePlan)
+	}
+
+	if r.DataError != "" {
+		fmt.Fprintf(&b, "Components: spec.data cannot be parsed: %s\n", r.DataError)
+		return strings.TrimSuffix(b.String(), "\n")
+	}
+
+	b.WriteString("Components:\n")
+	for _, component := range r.Components {
+		fmt.Fprintf(&b, "  %s\n", component.Name)
+		for _, repository := range component.Repositories {
+			fmt.Fprintf(&b, "    %s\n", repository.Url)
+			if len(repository.Tags) > 0 {
+				fmt.Fprintf(&b, "      Tags: %s\n", strings.Join(repository.Tags, ","))
+			}
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Repositories returns the repository URLs of every component
+func (r *ReleasePlanAdmissionSummary) Repositories() []string {
+	return lo.Uniq(lo.FlatMap(r.Components, func(c ReleasePlanAdmissionDataComponent, _ int) []string {
+		return lo.Map(c.Repositories, func(repo Repository, _ int) string { return repo.Url })
+	}))
+}
+
+// ReleasePlanAdmissionSummaryList is a list of ReleasePlanAdmissions sorted by namespace and name
+type ReleasePlanAdmissionSummaryList []ReleasePlanAdmissionSummary
+
+var releasePlanAdmissionColumns = []output.Column{
+	{Name: "NAMESPACE"}, {Name: "NAME"}, {Name: "ORIGIN"}, {Name: "APPLICATIONS"}, {Name: "POLICY"},
+	{Name: "RELEASEPLANS"}, {Name: "REPOSITORIES"},
+	{Name: "PIPELINE", Wide: true}, {Name: "CREATED", Wide: true},
+}
+
+func (l ReleasePlanAdmissionSummaryList) Table() *output.Table {
+	return &output.Table{
+		Columns: releasePlanAdmissionColumns,
+		Rows: lo.Map(l, func(r ReleasePlanAdmissionSummary, _ int) []string {
+			return []string{r.Namespace, r.Name, r.Origin, strings.Join(r.Applications, ","), r.Policy,
+				fmt.Sprint(len(r.ReleasePlans)), fmt.Sprint(len(r.Repositories())),
+				cell(r.Pipeline), timeCell(&r.CreationTime)}
+		}),
+	}
+}
+
+func (l ReleasePlanAdmissionSummaryList) Names() []string {
+	return lo.Map(l, func(r ReleasePlanAdmissionSummary, _ int) string {
+		return releaseServiceName("releaseplanadmission", r.Name)
+	})
+}
+
+// Table is the one row table of the ReleasePlanAdmission, see ReleasePlanAdmissionSummaryList.Table
+func (r *ReleasePlanAdmissionSummary) Table() *output.Table {
+	return ReleasePlanAdmissionSummaryList{*r}.Table()
+}
+
+func (r *ReleasePlanAdmissionSummary) Names() []string {
+	return ReleasePlanAdmissionSummaryList{*r}.Names()
+}
+
+// ListReleasePlanAdmissions returns the ReleasePlanAdmissions of the given namespaces,
+// or of all namespaces the user can read when none is given
+func ListReleasePlanAdmissions(ctx context.Context, k8sClient client.Client, namespaces []string) (ReleasePlanAdmissionSummaryList, error) {
+	rpas, err := listReleasePlanAdmissions(ctx, k8sClient, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	result := ReleasePlanAdmissionSummaryList(lo.Map(rpas, func(rpa konfluxapi.ReleasePlanAdmission, _ int) ReleasePlanAdmissionSummary {
+		return summarizeReleasePlanAdmission(&rpa)
+	}))
+
+	sortReleasePlanAdmissions(result)
+	return result, nil
+}
+
+// GetReleasePlanAdmission returns the ReleasePlanAdmission with its component to repository mapping
+func GetReleasePlanAdmission(ctx context.Context, k8sClient client.Client, namespace, name string) (*ReleasePlanAdmissionSummary, error) {
+	rpa := &konfluxapi.ReleasePlanAdmission{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, rpa); err != nil {
+		return nil, err
+	}
+
+	summary := summarizeReleasePlanAdmission(rpa)
+	return &summary, nil
+}
+
+// FindReleasePlanAdmissionsByRepository returns the ReleasePlanAdmissions publishing to the repository.
+// Tags and digests of the repository reference are ignored.
+func FindReleasePlanAdmissionsByRepository(ctx context.Context, k8sClient client.Client, repository string, namespaces []string) (ReleasePlanAdmissionSummaryList, error) {
+	repositoryName, err := utils.ParseRepository(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	rpas, err := ListReleasePlanAdmissions(ctx, k8sClient, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Filter(rpas, func(rpa ReleasePlanAdmissionSummary, _ int) bool {
+		return lo.ContainsBy(rpa.Repositories(), func(url string) bool {
+			name, err := utils.ParseRepository(url)
+			return err == nil && name == repositoryName
+		})
+	}), nil
+}
+
+func summarizeReleasePlanAdmission(rpa *konfluxapi.ReleasePlanAdmission) ReleasePlanAdmissionSummary {
+	summary := ReleasePlanAdmissionSummary{
+		Name:         rpa.Name,
+		Namespace:    rpa.Namespace,
+		Origin:       rpa.Spec.Origin,
+		Applications: rpa.Spec.Applications,
+		Policy:       rpa.Spec.Policy,
+		Pipeline:     pipelineString(rpa.Spec.Pipeline),
+		CreationTime: rpa.CreationTimestamp,
+		ReleasePlans: lo.Map(rpa.Status.ReleasePlans, func(rp konfluxapi.MatchedReleasePlan, _ int) string { return rp.Name }),
+		Components:   []ReleasePlanAdmissionDataComponent{},
+	}
+
+	if rpa.Spec.Data == nil {
+		return summary
+	}
+
+	var data ReleasePlanAdmissionData
+	if err := json.Unmarshal(rpa.Spec.Data.Raw, &data); err != nil {
+		summary.DataError = err.Error()
+		return summary
+	}
+	if data.Mappping.Components != nil {
+		summary.Components = data.Mappping.Components
+	}
+
+	return summary
+}
+
+// pipelineString renders the pipeline reference: url@revision:pathInRepo for the git resolver,
+// the resolver name with its params otherwise
+func pipelineString(pipeline *tektonutils.Pipeline) string {
+	if pipeline == nil {
+		return ""
+	}
+
+	if url, revision, pathInRepo, err := pipeline.PipelineRef.GetGitResolverParams(); err == nil {
+		return fmt.Sprintf("%s@%s:%s", url, revision, pathInRepo)
+	}
+
+	params := lo.Map(pipeline.PipelineRef.Params, func(p tektonutils.Param, _ int) string {
+		return fmt.Sprintf("%s=%s", p.Name, p.Value)
+	})
+	return fmt.Sprintf("%s(%s)", pipeline.PipelineRef.Resolver, strings.Join(params, ","))
+}
+
+func sortReleasePlanAdmissions(rpas ReleasePlanAdmissionSummaryList) {
+	sort.SliceStable(rpas, func(i, j int) bool {
+		return rpas[i].Namespace+"/"+rpas[i].Name < rpas[j].Namespace+"/"+rpas[j].Name
+	})
+}
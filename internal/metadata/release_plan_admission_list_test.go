@@ -0,0 +1,86 @@
+package metadata
+
+import (
+	"context"
+
+	tektonutils "github.com/konflux-ci/release-service/tekton/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("ReleasePlanAdmission inspection", func() {
+	var (
+		ctx       context.Context
+		k8sClient client.Client
+	)
+
+	rpaNames := func(rpas ReleasePlanAdmissionSummaryList) []string {
+		return lo.Map(rpas, func(r ReleasePlanAdmissionSummary, _ int) string { return r.Namespace + "/" + r.Name })
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		rpa := testRPA("my-rpa", "my-rp")
+		rpa.Spec.Pipeline = &tektonutils.Pipeline{PipelineRef: tektonutils.PipelineRef{
+			Resolver: "git",
+			Params: []tektonutils.Param{
+				{Name: "url", Value: "https://github.com/konflux-ci/release-service-catalog"},
+				{Name: "revision", Value: "production"},
+				{Name: "pathInRepo", Value: "pipelines/managed/rh-push-to-registry-redhat-io/rh-push-to-registry-redhat-io.yaml"},
+			},
+		}}
+		otherRPA := testRPA("other-rpa")
+		otherRPA.Namespace = "managed-release-team"
+		otherRPA.Spec.Data = rawJSON(ReleasePlanAdmissionData{Mappping: ReleasePlanAdmissionDataMapping{
+			Components: []ReleasePlanAdmissionDataComponent{{
+				Name:         "other-component",
+				Repositories: []Repository{{Url: "quay.io/org/other"}},
+			}},
+		}})
+		brokenRPA := testRPA("broken-rpa")
+		brokenRPA.Spec.Data = &k8sruntime.RawExtension{Raw: []byte(`{"mapping":{"components":"none"}}`)}
+		k8sClient = testClient(rpa, otherRPA, brokenRPA)
+	})
+
+	It("lists every ReleasePlanAdmission sorted by namespace and name", func() {
+		rpas, err := ListReleasePlanAdmissions(ctx, k8sClient, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rpaNames(rpas)).To(Equal([]string{
+			"managed-release-team/other-rpa",
+			testManagedNamespace + "/broken-rpa",
+			testManagedNamespace + "/my-rpa",
+		}))
+		Expect(rpas[1].DataError).ToNot(BeEmpty())
+		Expect(rpas[1].String()).To(ContainSubstring("spec.data cannot be parsed"))
+	})
+
+	It("returns the mapping, the matched plans and the pipeline", func() {
+		rpa, err := GetReleasePlanAdmission(ctx, k8sClient, testManagedNamespace, "my-rpa")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rpa.Policy).To(Equal("standard"))
+		Expect(rpa.ReleasePlans).To(Equal([]string{testTenantNamespace + "/my-rp"}))
+		Expect(rpa.Pipeline).To(Equal("https://github.com/konflux-ci/release-service-catalog@production:pipelines/managed/rh-push-to-registry-redhat-io/rh-push-to-registry-redhat-io.yaml"))
+		Expect(rpa.Repositories()).To(Equal([]string{testRepository}))
+		Expect(rpa.String()).To(ContainSubstring("  my-component\n    quay.io/org/my-app\n      Tags: latest,1.0"))
+	})
+
+	DescribeTable("finds the ReleasePlanAdmissions publishing to a repository",
+		func(repository string, expected []string) {
+			rpas, err := FindReleasePlanAdmissionsByRepository(ctx, k8sClient, repository, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rpaNames(rpas)).To(Equal(expected))
+		},
+		Entry("by repository", testRepository, []string{testManagedNamespace + "/my-rpa"}),
+		Entry("ignoring the tag", testRepository+":1.0", []string{testManagedNamespace + "/my-rpa"}),
+		Entry("ignoring the digest", testRepository+"@"+testDigest, []string{testManagedNamespace + "/my-rpa"}),
+		Entry("unknown repository", "quay.io/org/unknown", []string{}),
+	)
+
+	It("rejects invalid repositories", func() {
+		_, err := FindReleasePlanAdmissionsByRepository(ctx, k8sClient, "Quay.io/Org/IMG", nil)
+		Expect(err).To(MatchError(ContainSubstring("error parsing repository")))
+	})
+})
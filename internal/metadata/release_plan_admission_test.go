@@ -0,0 +1,80 @@
+package metadata
+
+import (
+	"context"
+
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("ReleasePlanAdmissionList", func() {
+	var (
+		ctx  context.Context
+		objs []client.Object
+	)
+
+	rpaNames := func(elements []Element) []string {
+		return lo.Map(elements, func(e Element, _ int) string { return e.String() })
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		otherRPA := testRPA("other-rpa")
+		otherRPA.Namespace = "managed-release-team"
+		objs = []client.Object{
+			testRPA("my-rpa"),
+			otherRPA,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testManagedNamespace}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "managed-release-team"}},
+		}
+	})
+
+	It("looks up the given namespaces", func() {
+		k8sClient := testClient(objs...)
+		rpaList, err := ReleasePlanAdmissionList(ctx, k8sClient, testRepository, []string{"managed-release-team"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rpaNames(rpaList)).To(Equal([]string{"ReleasePlanAdmission: other-rpa"}))
+	})
+
+	It("looks up all namespaces when none is given", func() {
+		k8sClient := testClient(objs...)
+		rpaList, err := ReleasePlanAdmissionList(ctx, k8sClient, testRepository, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rpaNames(rpaList)).To(ConsistOf("ReleasePlanAdmission: my-rpa", "ReleasePlanAdmission: other-rpa"))
+	})
+
+	It("falls back to readable namespaces when the cluster wide list is forbidden", func() {
+		k8sClient := fake.NewClientBuilder().WithScheme(testScheme()).WithObjects(objs...).
+			WithInterceptorFuncs(interceptor.Funcs{
+				List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+					listOpts := &client.ListOptions{}
+					listOpts.ApplyOptions(opts)
+					_, isRPAList := list.(*konfluxapi.ReleasePlanAdmissionList)
+					if isRPAList && listOpts.Namespace != "managed-release-team" {
+						return apierrors.NewForbidden(schema.GroupResource{Resource: "releaseplanadmissions"}, "", nil)
+					}
+					return c.List(ctx, list, opts...)
+				},
+			}).Build()
+
+		rpaList, err := ReleasePlanAdmissionList(ctx, k8sClient, testRepository, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rpaNames(rpaList)).To(Equal([]string{"ReleasePlanAdmission: other-rpa"}))
+	})
+
+	It("skips ReleasePlanAdmissions not mapping the repository", func() {
+		k8sClient := testClient(objs...)
+		rpaList, err := ReleasePlanAdmissionList(ctx, k8sClient, "quay.io/org/unknown", nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rpaList).To(BeEmpty())
+	})
+})
@@ -0,0 +1,175 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	releasemetadata "github.com/konflux-ci/release-service/metadata"
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/eguzki/konfluxctl/internal/output"
+)
+
+// ReleasePlanSummary is one release plan with its match status and its last releases
+type ReleasePlanSummary struct {
+	Name         string      `json:"name"`
+	Namespace    string      `json:"namespace"`
+	Application  string      `json:"application"`
+	Target       string      `json:"target"`
+	AutoRelease  bool        `json:"autoRelease"`
+	CreationTime metav1.Time `json:"creationTime"`
+	// ReleasePlanAdmission is the matched ReleasePlanAdmission, as namespace/name
+	ReleasePlanAdmission string `json:"releasePlanAdmission,omitempty"`
+	Matched              bool   `json:"matched"`
+	// NotMatchedReason explains why the Matched condition is not true
+	NotMatchedReason string `json:"notMatchedReason,omitempty"`
+	// Releases are the last releases created from the plan, most recent first
+	Releases ReleaseSummaryList `json:"releases"`
+}
+
+func (r *ReleasePlanSummary) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ReleasePlan: %s/%s\nApplication: %s\nTarget: %s\nAuto Release: %t\nCreated: %s\n",
+		r.Namespace, r.Name, r.Application, lo.Ternary(r.Target != "", r.Target, "<none>"), r.AutoRelease,
+		completionTimeString(&r.CreationTime))
+
+	if r.Matched {
+		fmt.Fprintf(&b, "Matched: %s\n", r.ReleasePlanAdmission)
+	} else {
+		fmt.Fprintf(&b, "Matched: false, %s\n", r.NotMatchedReason)
+	}
+
+	b.WriteString("Releases:")
+	if len(r.Releases) == 0 {
+		b.WriteString(" <none>")
+	}
+	for _, release := range r.Releases {
+		fmt.Fprintf(&b, "\n  %s: %s (snapshot: %s, created: %s)", release.Name, release.Status, release.Snapshot,
+			completionTimeString(&release.CreationTime))
+	}
+
+	return b.String()
+}
+
+// ReleasePlanSummaryList is a list of release plans sorted by namespace and name
+type ReleasePlanSummaryList []ReleasePlanSummary
+
+var releasePlanColumns = []output.Column{
+	{Name: "NAMESPACE"}, {Name: "NAME"}, {Name: "APPLICATION"}, {Name: "TARGET"}, {Name: "AUTO RELEASE"},
+	{Name: "RELEASE PLAN ADMISSION"}, {Name: "LAST RELEASE"}, {Name: "STATUS"},
+	{Name: "CREATED", Wide: true}, {Name: "NOT MATCHED REASON", Wide: true},
+}
+
+func (l ReleasePlanSummaryList) Table() *output.Table {
+	return &output.Table{
+		Columns: releasePlanColumns,
+		Rows: lo.Map(l, func(r ReleasePlanSummary, _ int) []string {
+			rpa := lo.Ternary(r.Matched, r.ReleasePlanAdmission, "<not matched>")
+			lastRelease, status := "-", "-"
+			if len(r.Releases) > 0 {
+				lastRelease, status = r.Releases[0].Name, string(r.Releases[0].Status)
+			}
+			return []string{r.Namespace, r.Name, r.Application, cell(r.Target), fmt.Sprint(r.AutoRelease),
+				rpa, lastRelease, status, timeCell(&r.CreationTime), cell(r.NotMatchedReason)}
+		}),
+	}
+}
+
+func (l ReleasePlanSummaryList) Names() []string {
+	return lo.Map(l, func(r ReleasePlanSummary, _ int) string { return releaseServiceName("releaseplan", r.Name) })
+}
+
+// Table is the one row table of the release plan, see ReleasePlanSummaryList.Table
+func (r *ReleasePlanSummary) Table() *output.Table {
+	return ReleasePlanSummaryList{*r}.Table()
+}
+
+func (r *ReleasePlanSummary) Names() []string {
+	return ReleasePlanSummaryList{*r}.Names()
+}
+
+// ListReleasePlans returns the release plans of the application, every plan when the application is empty,
+// with the last releases created from each plan. Release plans are looked up in the given namespaces,
+// or across all namespaces the user can read when none is given.
+func ListReleasePlans(ctx context.Context, k8sClient client.Client, application string, lastReleases int, namespaces []string) (ReleasePlanSummaryList, error) {
+	lists, err := listInNamespaces(ctx, k8sClient, namespaces, func() *konfluxapi.ReleasePlanList {
+		return &konfluxapi.ReleasePlanList{}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	releasePlans := lo.FlatMap(lists, func(l *konfluxapi.ReleasePlanList, _ int) []konfluxapi.ReleasePlan { return l.Items })
+	releasePlans = lo.Filter(releasePlans, func(rp konfluxapi.ReleasePlan, _ int) bool {
+		return application == "" || rp.Spec.Application == application
+	})
+
+	releases := ReleaseSummaryList{}
+	if lastReleases > 0 && len(releasePlans) > 0 {
+		releases, err = ListReleases(ctx, k8sClient, ReleaseFilter{Application: application}, namespaces)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := ReleasePlanSummaryList(lo.Map(releasePlans, func(rp konfluxapi.ReleasePlan, _ int) ReleasePlanSummary {
+		return summarizeReleasePlan(&rp, releases, lastReleases)
+	}))
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Namespace+"/"+result[i].Name < result[j].Namespace+"/"+result[j].Name
+	})
+	return result, nil
+}
+
+// GetReleasePlan returns the release plan with the last releases created from it
+func GetReleasePlan(ctx context.Context, k8sClient client.Client, namespace, name string, lastReleases int) (*ReleasePlanSummary, error) {
+	releasePlan := &konfluxapi.ReleasePlan{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, releasePlan); err != nil {
+		return nil, err
+	}
+
+	releases := ReleaseSummaryList{}
+	if lastReleases > 0 {
+		var err error
+		releases, err = ListReleases(ctx, k8sClient, ReleaseFilter{ReleasePlan: name}, []string{namespace})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	summary := summarizeReleasePlan(releasePlan, releases, lastReleases)
+	return &summary, nil
+}
+
+// summarizeReleasePlan takes the first lastReleases releases of the plan, releases are sorted most recent first
+func summarizeReleasePlan(releasePlan *konfluxapi.ReleasePlan, releases ReleaseSummaryList, lastReleases int) ReleasePlanSummary {
+	summary := ReleasePlanSummary{
+		Name:                 releasePlan.Name,
+		Namespace:            releasePlan.Namespace,
+		Application:          releasePlan.Spec.Application,
+		Target:               releasePlan.Spec.Target,
+		AutoRelease:          releasePlan.GetLabels()[releasemetadata.AutoReleaseLabel] == "true",
+		CreationTime:         releasePlan.CreationTimestamp,
+		ReleasePlanAdmission: releasePlan.Status.ReleasePlanAdmission.Name,
+		Matched:              meta.IsStatusConditionTrue(releasePlan.Status.Conditions, konfluxapi.MatchedConditionType.String()),
+	}
+
+	if !summary.Matched {
+		summary.NotMatchedReason = conditionNotTrueReason(releasePlan.Status.Conditions, konfluxapi.MatchedConditionType.String())
+	}
+
+	// same selection as ReleasePlanElement.Children
+	planReleases := lo.Filter(releases, func(release ReleaseSummary, _ int) bool {
+		return release.Namespace == releasePlan.Namespace && release.ReleasePlan == releasePlan.Name
+	})
+	summary.Releases = lo.Subset(planReleases, 0, uint(max(lastReleases, 0)))
+
+	return summary
+}
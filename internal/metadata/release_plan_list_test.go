@@ -0,0 +1,81 @@
+package metadata
+
+import (
+	"context"
+	"time"
+
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	releasemetadata "github.com/konflux-ci/release-service/metadata"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("ReleasePlan inspection", func() {
+	var (
+		ctx       context.Context
+		k8sClient client.Client
+	)
+
+	releaseNames := func(releases ReleaseSummaryList) []string {
+		return lo.Map(releases, func(r ReleaseSummary, _ int) string { return r.Name })
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		t0 := time.Date(2025, time.May, 1, 10, 0, 0, 0, time.UTC)
+
+		releasePlan := testReleasePlan("my-rp", true)
+		releasePlan.Labels = map[string]string{releasemetadata.AutoReleaseLabel: "true"}
+		releasePlan.Status.ReleasePlanAdmission = konfluxapi.MatchedReleasePlanAdmission{Name: testManagedNamespace + "/my-rpa", Active: true}
+		unmatched := testReleasePlan("unmatched-rp", false)
+		unmatched.Status.Conditions[0].Message = "no ReleasePlanAdmission found"
+
+		releases := []client.Object{}
+		for idx, name := range []string{"release-1", "release-2", "release-3"} {
+			release := testRelease(name, "my-rp", "snapshot-a", idx != 1, t0.Add(time.Duration(idx)*time.Hour))
+			release.CreationTimestamp = metav1.Time{Time: t0.Add(time.Duration(idx) * time.Hour)}
+			releases = append(releases, release)
+		}
+
+		k8sClient = testClient(append(releases, releasePlan, unmatched)...)
+	})
+
+	It("returns the plan with its last releases", func() {
+		releasePlan, err := GetReleasePlan(ctx, k8sClient, testTenantNamespace, "my-rp", 2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(releasePlan.Application).To(Equal("my-application"))
+		Expect(releasePlan.Target).To(Equal(testManagedNamespace))
+		Expect(releasePlan.AutoRelease).To(BeTrue())
+		Expect(releasePlan.Matched).To(BeTrue())
+		Expect(releasePlan.ReleasePlanAdmission).To(Equal(testManagedNamespace + "/my-rpa"))
+		Expect(releaseNames(releasePlan.Releases)).To(Equal([]string{"release-3", "release-2"}))
+		Expect(releasePlan.Releases[1].Status).To(Equal(ReleaseStatusFailed))
+		Expect(releasePlan.String()).To(ContainSubstring("release-2: failed (snapshot: snapshot-a"))
+	})
+
+	It("explains why the plan is not matched", func() {
+		releasePlan, err := GetReleasePlan(ctx, k8sClient, testTenantNamespace, "unmatched-rp", 5)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(releasePlan.Matched).To(BeFalse())
+		Expect(releasePlan.NotMatchedReason).To(Equal("condition Matched is False (reason: Matched): no ReleasePlanAdmission found"))
+		Expect(releasePlan.Releases).To(BeEmpty())
+	})
+
+	It("lists the plans with their last release", func() {
+		releasePlans, err := ListReleasePlans(ctx, k8sClient, "my-application", 1, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lo.Map(releasePlans, func(rp ReleasePlanSummary, _ int) string { return rp.Name })).To(
+			Equal([]string{"my-rp", "unmatched-rp"}))
+		Expect(releaseNames(releasePlans[0].Releases)).To(Equal([]string{"release-3"}))
+		Expect(releasePlans[1].Releases).To(BeEmpty())
+	})
+
+	It("filters the plans by application", func() {
+		releasePlans, err := ListReleasePlans(ctx, k8sClient, "other-application", 1, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(releasePlans).To(BeEmpty())
+	})
+})
@@ -0,0 +1,118 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReleaseFailedError is returned by WaitForRelease when the release finished without being released
+type ReleaseFailedError struct {
+	Name      string
+	Condition metav1.Condition
+}
+
+func (e *ReleaseFailedError) Error() string {
+	reason := conditionNotTrueReason([]metav1.Condition{e.Condition}, e.Condition.Type)
+	return fmt.Sprintf("release %s failed: %s", e.Name, reason)
+}
+
+// WaitForRelease watches the release until it finishes. Every condition transition is reported to onTransition,
+// the current conditions first. It returns nil when the release is Released, a ReleaseFailedError when it failed,
+// or the context error when the context is done first.
+func WaitForRelease(ctx context.Context, k8sClient client.WithWatch, namespace, name string, onTransition func(metav1.Condition)) error {
+	seen := map[string]metav1.Condition{}
+
+	for {
+		release := &konfluxapi.Release{}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, release); err != nil {
+			return err
+		}
+
+		if done, err := releaseTransition(release, seen, onTransition); done {
+			return err
+		}
+
+		watcher, err := k8sClient.Watch(ctx, &konfluxapi.ReleaseList{},
+			client.InNamespace(namespace),
+			client.MatchingFieldsSelector{Selector: fields.OneTermEqualSelector("metadata.name", name)},
+			&client.ListOptions{Raw: &metav1.ListOptions{ResourceVersion: release.ResourceVersion}},
+		)
+		if err != nil {
+			return err
+		}
+
+		done, err := watchRelease(ctx, watcher, name, seen, onTransition)
+		watcher.Stop()
+		if done {
+			return err
+		}
+
+		// the API server closes watches after a while, start over
+		slog.Debug("release watch closed, watching again", "release", name)
+	}
+}
+
+// watchRelease consumes the watch events until the release finishes or the watch is closed
+func watchRelease(ctx context.Context, watcher watch.Interface, name string, seen map[string]metav1.Condition, onTransition func(metav1.Condition)) (bool, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return false, nil
+			}
+
+			switch event.Type {
+			case watch.Deleted:
+				if release, ok := event.Object.(*konfluxapi.Release); ok && release.Name == name {
+					return true, fmt.Errorf("release %s was deleted", name)
+				}
+			case watch.Error:
+				// usually an expired resource version, start over
+				slog.Debug("release watch error", "error", event.Object)
+				return false, nil
+			case watch.Added, watch.Modified:
+				release, ok := event.Object.(*konfluxapi.Release)
+				if !ok || release.Name != name {
+					continue
+				}
+				if done, err := releaseTransition(release, seen, onTransition); done {
+					return true, err
+				}
+			}
+		}
+	}
+}
+
+// releaseTransition reports the condition changes not seen yet, and whether the release has finished
+func releaseTransition(release *konfluxapi.Release, seen map[string]metav1.Condition, onTransition func(metav1.Condition)) (bool, error) {
+	for _, condition := range release.Status.Conditions {
+		previous, ok := seen[condition.Type]
+		if ok && previous.Status == condition.Status && previous.Reason == condition.Reason && previous.Message == condition.Message {
+			continue
+		}
+		seen[condition.Type] = condition
+		onTransition(condition)
+	}
+
+	switch {
+	case release.IsReleased():
+		return true, nil
+	case release.IsFailed():
+		return true, &ReleaseFailedError{
+			Name:      release.Name,
+			Condition: *meta.FindStatusCondition(release.Status.Conditions, "Released"),
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,94 @@
+package metadata
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("WaitForRelease", func() {
+	var (
+		ctx         context.Context
+		k8sClient   client.WithWatch
+		watching    chan struct{}
+		mu          sync.Mutex
+		transitions []string
+	)
+
+	onTransition := func(condition metav1.Condition) {
+		mu.Lock()
+		defer mu.Unlock()
+		transitions = append(transitions, condition.Type+"="+string(condition.Status))
+	}
+
+	progressingRelease := func() *konfluxapi.Release {
+		release := testRelease("my-release", "my-rp", "snapshot-a", true, time.Now())
+		release.Status.CompletionTime = nil
+		release.Status.Conditions = []metav1.Condition{
+			{Type: "Validated", Status: metav1.ConditionTrue, Reason: "Succeeded"},
+			{Type: "Released", Status: metav1.ConditionFalse, Reason: konfluxapi.ProgressingReason.String()},
+		}
+		return release
+	}
+
+	// finish updates the release conditions once the watch is established
+	finish := func(status metav1.ConditionStatus, reason, message string) {
+		go func() {
+			defer GinkgoRecover()
+			Eventually(watching).Should(BeClosed())
+
+			release := &konfluxapi.Release{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: testTenantNamespace, Name: "my-release"}, release)).To(Succeed())
+			release.Status.Conditions[1] = metav1.Condition{Type: "Released", Status: status, Reason: reason, Message: message}
+			Expect(k8sClient.Update(ctx, release)).To(Succeed())
+		}()
+	}
+
+	BeforeEach(func() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+		transitions = nil
+		watching = make(chan struct{})
+		watchStarted := sync.OnceFunc(func() { close(watching) })
+		k8sClient = fake.NewClientBuilder().WithScheme(testScheme()).WithObjects(progressingRelease()).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Watch: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) (watch.Interface, error) {
+					defer watchStarted()
+					return c.Watch(ctx, list, opts...)
+				},
+			}).Build()
+	})
+
+	It("returns when the release is released", func() {
+		finish(metav1.ConditionTrue, konfluxapi.SucceededReason.String(), "")
+
+		Expect(WaitForRelease(ctx, k8sClient, testTenantNamespace, "my-release", onTransition)).To(Succeed())
+		Expect(transitions).To(Equal([]string{"Validated=True", "Released=False", "Released=True"}))
+	})
+
+	It("fails with the reason when the release failed", func() {
+		finish(metav1.ConditionFalse, konfluxapi.FailedReason.String(), "managed pipeline failed")
+
+		err := WaitForRelease(ctx, k8sClient, testTenantNamespace, "my-release", onTransition)
+		Expect(err).To(BeAssignableToTypeOf(&ReleaseFailedError{}))
+		Expect(err).To(MatchError(ContainSubstring("managed pipeline failed")))
+	})
+
+	It("stops when the context is done", func() {
+		timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancel()
+
+		err := WaitForRelease(timeoutCtx, k8sClient, testTenantNamespace, "my-release", onTransition)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+})
@@ -0,0 +1,268 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	"github.com/samber/lo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/eguzki/konfluxctl/internal/output"
+	"github.com/eguzki/konfluxctl/internal/utils"
+)
+
+// minShortRevisionLength is the shortest abbreviated git revision accepted, as git does by default
+const minShortRevisionLength = 7
+
+// ReleasedImage is one image published to one repository by a release of some source revision.
+// It walks the lineage graph the other way around: from the source to the repository.
+type ReleasedImage struct {
+	// Image is the published image, by digest
+	Image                 string       `json:"image"`
+	Repository            string       `json:"repository"`
+	Tags                  []string     `json:"tags"`
+	Advisory              *string      `json:"advisory"`
+	Release               string       `json:"release"`
+	ReleaseCompletionTime *metav1.Time `json:"releaseCompletionTime"`
+	ReleasePlan           string       `json:"releasePlan"`
+	ReleasePlanAdmission  string       `json:"releasePlanAdmission"`
+	Snapshot              string       `json:"snapshot"`
+	Application           string       `json:"application"`
+	ComponentName         string       `json:"componentName"`
+	SourceURL             string       `json:"sourceURL"`
+	SourceRevision        string       `json:"sourceRevision"`
+}
+
+func (r ReleasedImage) String() string {
+	return fmt.Sprintf(`Image: %s
+Tags: %s
+Advisory: %s
+Release: %s
+Release Completion Time: %s
+ReleasePlan: %s
+ReleasePlanAdmission: %s
+Snapshot: %s
+Application: %s
+Component: %s
+Source Revision: %s`,
+		r.Image,
+		orNone(strings.Join(r.Tags, ",")),
+		lo.FromPtrOr(r.Advisory, "<none>"),
+		r.Release,
+		completionTimeString(r.ReleaseCompletionTime),
+		r.ReleasePlan,
+		r.ReleasePlanAdmission,
+		r.Snapshot,
+		r.Application,
+		r.ComponentName,
+		r.SourceRevision,
+	)
+}
+
+// ReleasedImageList is the set of images released from one source revision,
+// most recently released first.
+type ReleasedImageList []ReleasedImage
+
+func (l ReleasedImageList) String() string {
+	blocks := lo.Map(l, func(r ReleasedImage, idx int) string {
+		return fmt.Sprintf("Image %d/%d\n%s", idx+1, len(l), r)
+	})
+	return strings.Join(blocks, "\n\n")
+}
+
+var releasedImageColumns = []output.Column{
+	{Name: "IMAGE"}, {Name: "TAGS"}, {Name: "ADVISORY"}, {Name: "RELEASE"}, {Name: "COMPLETED"},
+	{Name: "APPLICATION", Wide: true}, {Name: "COMPONENT", Wide: true}, {Name: "SNAPSHOT", Wide: true},
+	{Name: "RELEASE PLAN", Wide: true}, {Name: "RELEASE PLAN ADMISSION", Wide: true},
+}
+
+func (l ReleasedImageList) Table() *output.Table {
+	return &output.Table{
+		Columns: releasedImageColumns,
+		Rows: lo.Map(l, func(r ReleasedImage, _ int) []string {
+			return []string{r.Image, cell(strings.Join(r.Tags, ",")), lo.FromPtrOr(r.Advisory, "-"), r.Release,
+				timeCell(r.ReleaseCompletionTime), r.Application, r.ComponentName, r.Snapshot, r.ReleasePlan, r.ReleasePlanAdmission}
+		}),
+	}
+}
+
+// SourceReleasedImages returns the images released from the source revision.
+// Snapshots with a component built from the source are looked up in the given namespaces, or across all
+// namespaces the user can read when none is given. Then, the Released releases of those snapshots are
+// followed to the ReleasePlanAdmissions mapping the component to the published repositories.
+// Abbreviated revisions are accepted.
+func SourceReleasedImages(ctx context.Context, k8sClient client.Client, sourceURL, revision string, namespaces []string) (ReleasedImageList, error) {
+	if len(revision) < minShortRevisionLength {
+		return nil, fmt.Errorf("revision %q is too short, at least %d characters are required", revision, minShortRevisionLength)
+	}
+
+	lists, err := listInNamespaces(ctx, k8sClient, namespaces, func() *applicationapi.SnapshotList {
+		return &applicationapi.SnapshotList{}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := ReleasedImageList{}
+	for _, list := range lists {
+		for idx := range list.Items {
+			snapshot := &list.Items[idx]
+			for _, component := range snapshot.Spec.Components {
+				if !builtFromSource(component, sourceURL, revision) {
+					continue
+				}
+
+				slog.Debug("source released", "snapshot", snapshot.Name, "component", component.Name)
+
+				images, err := snapshotReleasedImages(ctx, k8sClient, snapshot, component)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, images...)
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		ti, tj := result[i].ReleaseCompletionTime, result[j].ReleaseCompletionTime
+		switch {
+		case ti != nil && tj == nil:
+			return true
+		case ti == nil && tj != nil:
+			return false
+		case ti != nil && tj != nil && !ti.Equal(tj):
+			return tj.Before(ti)
+		}
+		return result[i].Release+"/"+result[i].Image < result[j].Release+"/"+result[j].Image
+	})
+
+	return result, nil
+}
+
+// snapshotReleasedImages follows the Released releases of the snapshot to the published repositories of the component
+func snapshotReleasedImages(ctx context.Context, k8sClient client.Client, snapshot *applicationapi.Snapshot, component applicationapi.SnapshotComponent) ([]ReleasedImage, error) {
+	containerImageURL, err := utils.ParseImageURL(component.ContainerImage)
+	if err != nil {
+		slog.Debug("source released", "snapshot", snapshot.Name, "component", component.Name, "error", err)
+		return nil, nil
+	}
+
+	releaseList := &konfluxapi.ReleaseList{}
+	if err := k8sClient.List(ctx, releaseList, client.InNamespace(snapshot.Namespace)); err != nil {
+		return nil, err
+	}
+
+	result := []ReleasedImage{}
+	for idx := range releaseList.Items {
+		release := &releaseList.Items[idx]
+		if release.Spec.Snapshot != snapshot.Name || !meta.IsStatusConditionTrue(release.Status.Conditions, "Released") {
+			continue
+		}
+
+		releasePlan := &konfluxapi.ReleasePlan{}
+		err := k8sClient.Get(ctx, client.ObjectKey{Namespace: release.Namespace, Name: release.Spec.ReleasePlan}, releasePlan)
+		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+			skipRelease(ctx, release, fmt.Sprintf("ReleasePlan %s/%s", release.Namespace, release.Spec.ReleasePlan), err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		rpaNamespace, rpaName, ok := strings.Cut(releasePlan.Status.ReleasePlanAdmission.Name, "/")
+		if !ok {
+			slog.Debug("source released", "releaseplan", releasePlan.Name, "error", "no ReleasePlanAdmission matched")
+			continue
+		}
+
+		rpa := &konfluxapi.ReleasePlanAdmission{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: rpaNamespace, Name: rpaName}, rpa)
+		// the managed namespace is usually not readable by the tenant
+		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+			skipRelease(ctx, release, fmt.Sprintf("ReleasePlanAdmission %s/%s", rpaNamespace, rpaName), err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, repository := range componentRepositories(rpa, component.Name) {
+			result = append(result, ReleasedImage{
+				Image:                 fmt.Sprintf("%s@%s", repository.Url, containerImageURL.Digest()),
+				Repository:            repository.Url,
+				Tags:                  repository.Tags,
+				Advisory:              releaseAdvisory(release),
+				Release:               release.Name,
+				ReleaseCompletionTime: release.Status.CompletionTime,
+				ReleasePlan:           releasePlan.Name,
+				ReleasePlanAdmission:  rpa.Name,
+				Snapshot:              snapshot.Name,
+				Application:           snapshot.Spec.Application,
+				ComponentName:         component.Name,
+				SourceURL:             component.Source.GitSource.URL,
+				SourceRevision:        component.Source.GitSource.Revision,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// skipRelease reports a release left out because an object it was released with cannot be read
+func skipRelease(ctx context.Context, release *konfluxapi.Release, object string, err error) {
+	slog.Debug("source released, release skipped", "release", release.Name, "object", object, "error", err)
+	traceNote(ctx, "Release %s/%s skipped, %s not available: %s", release.Namespace, release.Name, object, apierrors.ReasonForError(err))
+}
+
+// componentRepositories returns the repositories the ReleasePlanAdmission publishes the component to
+func componentRepositories(rpa *konfluxapi.ReleasePlanAdmission, componentName string) []Repository {
+	if rpa.Spec.Data == nil {
+		return nil
+	}
+
+	var data ReleasePlanAdmissionData
+	if err := json.Unmarshal(rpa.Spec.Data.Raw, &data); err != nil {
+		slog.Debug("source released", "releaseplanadmission", rpa.Name, "error", err)
+		return nil
+	}
+
+	return lo.FlatMap(data.Mappping.Components, func(comp ReleasePlanAdmissionDataComponent, _ int) []Repository {
+		if comp.Name != componentName {
+			return nil
+		}
+		return comp.Repositories
+	})
+}
+
+func builtFromSource(component applicationapi.SnapshotComponent, sourceURL, revision string) bool {
+	gitSource := component.Source.GitSource
+	if gitSource == nil {
+		return false
+	}
+
+	return normalizeGitURL(gitSource.URL) == normalizeGitURL(sourceURL) &&
+		strings.HasPrefix(strings.ToLower(gitSource.Revision), strings.ToLower(revision))
+}
+
+// normalizeGitURL makes equivalent git URLs comparable:
+// https://github.com/org/repo.git, git@github.com:org/repo and github.com/org/repo/ are the same
+func normalizeGitURL(gitURL string) string {
+	normalized := strings.ToLower(strings.TrimSpace(gitURL))
+	for _, prefix := range []string{"https://", "http://", "ssh://", "git://"} {
+		normalized = strings.TrimPrefix(normalized, prefix)
+	}
+	if user, rest, ok := strings.Cut(normalized, "@"); ok && !strings.Contains(user, "/") {
+		normalized = strings.Replace(rest, ":", "/", 1)
+	}
+	normalized = strings.TrimSuffix(normalized, "/")
+	return strings.TrimSuffix(normalized, ".git")
+}
@@ -0,0 +1,118 @@
+package metadata
+
+import (
+	"context"
+	"time"
+
+	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
+	konfluxapi "github.com/konflux-ci/release-service/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("SourceReleasedImages", func() {
+	const revision = "0123456789abcdef0123456789abcdef01234567"
+
+	var (
+		ctx       context.Context
+		t0        time.Time
+		objs      []client.Object
+		k8sClient client.Client
+	)
+
+	snapshotFromRevision := func(name, digest, revision string) *applicationapi.Snapshot {
+		snapshot := testSnapshot(name, digest)
+		snapshot.Spec.Components[0].Source.GitSource.Revision = revision
+		return snapshot
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		t0 = time.Date(2025, time.May, 1, 10, 0, 0, 0, time.UTC)
+
+		releasePlan := testReleasePlan("my-rp", true)
+		releasePlan.Status.ReleasePlanAdmission.Name = testManagedNamespace + "/my-rpa"
+
+		objs = []client.Object{
+			testRPA("my-rpa", "my-rp"),
+			releasePlan,
+			testRelease("release-ga", "my-rp", "snapshot-a", true, t0),
+			testRelease("release-z", "my-rp", "snapshot-b", true, t0.Add(time.Hour)),
+			testRelease("release-failed", "my-rp", "snapshot-a", false, t0.Add(2*time.Hour)),
+			testRelease("release-other", "my-rp", "snapshot-other", true, t0.Add(3*time.Hour)),
+			snapshotFromRevision("snapshot-a", testDigest, revision),
+			snapshotFromRevision("snapshot-b", testDigest, revision),
+			snapshotFromRevision("snapshot-other", testOtherDigest, "fedcba9876543210fedcba9876543210fedcba98"),
+		}
+		k8sClient = testClient(objs...)
+	})
+
+	It("returns the images released from the revision, most recent release first", func() {
+		images, err := SourceReleasedImages(ctx, k8sClient, "https://github.com/org/my-app", revision, []string{testTenantNamespace})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lo.Map(images, func(r ReleasedImage, _ int) string { return r.Release })).To(
+			Equal([]string{"release-z", "release-ga"}))
+
+		latest := images[0]
+		Expect(latest.Image).To(Equal(testRepository + "@" + testDigest))
+		Expect(latest.Tags).To(Equal([]string{"latest", "1.0"}))
+		Expect(latest.Advisory).To(HaveValue(Equal("https://access.redhat.com/errata/release-z")))
+		Expect(latest.ReleasePlanAdmission).To(Equal("my-rpa"))
+		Expect(latest.Snapshot).To(Equal("snapshot-b"))
+		Expect(latest.ComponentName).To(Equal("my-component"))
+	})
+
+	It("accepts abbreviated revisions and equivalent git URLs", func() {
+		images, err := SourceReleasedImages(ctx, k8sClient, "git@github.com:org/my-app.git", revision[:7], nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(images).To(HaveLen(2))
+	})
+
+	It("rejects too short revisions", func() {
+		_, err := SourceReleasedImages(ctx, k8sClient, "https://github.com/org/my-app", "0123", nil)
+		Expect(err).To(MatchError(ContainSubstring("too short")))
+	})
+
+	It("skips releases whose ReleasePlanAdmission is forbidden", func() {
+		forbiddenClient := fake.NewClientBuilder().WithScheme(testScheme()).WithObjects(objs...).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if _, ok := obj.(*konfluxapi.ReleasePlanAdmission); ok {
+						return apierrors.NewForbidden(schema.GroupResource{Group: konfluxapi.GroupVersion.Group, Resource: "releaseplanadmissions"}, key.Name, nil)
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			}).Build()
+
+		trace := NewTrace("Source: my-app")
+		images, err := SourceReleasedImages(WithTrace(ctx, trace), forbiddenClient, "https://github.com/org/my-app", revision, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(images).To(BeEmpty())
+		Expect(trace.Root.Notes).To(ContainElement(
+			"Release " + testTenantNamespace + "/release-z skipped, ReleasePlanAdmission " + testManagedNamespace + "/my-rpa not available: Forbidden"))
+	})
+
+	It("returns nothing for unknown sources", func() {
+		images, err := SourceReleasedImages(ctx, k8sClient, "https://github.com/org/other", revision, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(images).To(BeEmpty())
+	})
+})
+
+var _ = DescribeTable("normalizeGitURL",
+	func(gitURL string) {
+		Expect(normalizeGitURL(gitURL)).To(Equal("github.com/org/repo"))
+	},
+	Entry("https", "https://github.com/org/repo"),
+	Entry("https with .git suffix", "https://github.com/Org/Repo.git"),
+	Entry("trailing slash", "https://github.com/org/repo/"),
+	Entry("scp like", "git@github.com:org/repo.git"),
+	Entry("ssh", "ssh://git@github.com/org/repo"),
+	Entry("no scheme", "github.com/org/repo"),
+)
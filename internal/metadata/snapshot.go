@@ -13,17 +13,28 @@ import (
 type SnapshotElement struct {
 	rawSnapshot *applicationapi.Snapshot
 	component   *applicationapi.SnapshotComponent
+	// platform is set when the image is a platform manifest of the component image index, or vice versa
+	platform string
 }
 
 func (s *SnapshotElement) String() string {
 	return fmt.Sprintf("%s: %s", "Snapshot", s.rawSnapshot.Name)
 }
 
+func (s *SnapshotElement) ID() string {
+	return objectID("Snapshot", s.rawSnapshot.Namespace, s.rawSnapshot.Name)
+}
+
 func (s *SnapshotElement) Visit(path *Path) {
 	path.Snapshot = &s.rawSnapshot.Name
 	path.ComponentName = &s.component.Name
-	path.SourceRevision = &s.component.Source.GitSource.Revision
-	path.SourceURL = &s.component.Source.GitSource.URL
+	if s.component.Source.GitSource != nil {
+		path.SourceRevision = &s.component.Source.GitSource.Revision
+		path.SourceURL = &s.component.Source.GitSource.URL
+	}
+	if s.platform != "" {
+		path.Platform = &s.platform
+	}
 }
 
 func (s *SnapshotElement) Children(ctx context.Context, k8sClient client.Client, imageURL *utils.ImageURL) ([]Element, error) {
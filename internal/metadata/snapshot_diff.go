@@ -0,0 +1,150 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/samber/lo"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/eguzki/konfluxctl/internal/utils"
+)
+
+// ComponentChangeType tells how a component changed between two snapshots
+type ComponentChangeType string
+
+const (
+	ComponentAdded   ComponentChangeType = "added"
+	ComponentRemoved ComponentChangeType = "removed"
+	ComponentChanged ComponentChangeType = "changed"
+)
+
+// ComponentChange is one component that differs between two snapshots
+type ComponentChange struct {
+	Name   string              `json:"name"`
+	Change ComponentChangeType `json:"change"`
+	// From is unset for added components
+	From *ComponentSummary `json:"from,omitempty"`
+	// To is unset for removed components
+	To              *ComponentSummary `json:"to,omitempty"`
+	ImageChanged    bool              `json:"imageChanged"`
+	RevisionChanged bool              `json:"revisionChanged"`
+}
+
+// SnapshotDiff compares the components of two snapshots
+type SnapshotDiff struct {
+	From    string            `json:"from"`
+	To      string            `json:"to"`
+	Changes []ComponentChange `json:"changes"`
+	// Unchanged lists the names of the components with the same image and revision
+	Unchanged []string `json:"unchanged"`
+}
+
+// String renders the diff like a unified diff header: + added, - removed, ~ changed
+func (d *SnapshotDiff) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", d.From, d.To)
+
+	for _, change := range d.Changes {
+		switch change.Change {
+		case ComponentAdded:
+			fmt.Fprintf(&b, "+ %s\n    Image: %s\n", change.Name, change.To.Image)
+			if change.To.SourceRevision != "" {
+				fmt.Fprintf(&b, "    Revision: %s\n", change.To.SourceRevision)
+			}
+		case ComponentRemoved:
+			fmt.Fprintf(&b, "- %s\n", change.Name)
+		case ComponentChanged:
+			fmt.Fprintf(&b, "~ %s\n", change.Name)
+			if change.ImageChanged {
+				fmt.Fprintf(&b, "    Image: %s -> %s\n", imageDigest(change.From.Image), imageDigest(change.To.Image))
+			}
+			if change.RevisionChanged {
+				fmt.Fprintf(&b, "    Revision: %s -> %s\n", change.From.SourceRevision, change.To.SourceRevision)
+			}
+		}
+	}
+
+	fmt.Fprintf(&b, "%d differ, %d unchanged", len(d.Changes), len(d.Unchanged))
+	return b.String()
+}
+
+// DiffSnapshots compares the components of the snapshots by name.
+// Images are compared by digest, the repository of the built image may change with no rebuild.
+func DiffSnapshots(from, to *applicationapi.Snapshot) *SnapshotDiff {
+	diff := &SnapshotDiff{
+		From:      from.Name,
+		To:        to.Name,
+		Changes:   []ComponentChange{},
+		Unchanged: []string{},
+	}
+
+	fromComponents := lo.KeyBy(componentSummaries(from), func(c ComponentSummary) string { return c.Name })
+	toComponents := lo.KeyBy(componentSummaries(to), func(c ComponentSummary) string { return c.Name })
+
+	names := lo.Uniq(append(lo.Keys(fromComponents), lo.Keys(toComponents)...))
+	sort.Strings(names)
+
+	for _, name := range names {
+		fromComponent, inFrom := fromComponents[name]
+		toComponent, inTo := toComponents[name]
+
+		switch {
+		case !inFrom:
+			diff.Changes = append(diff.Changes, ComponentChange{Name: name, Change: ComponentAdded, To: &toComponent})
+		case !inTo:
+			diff.Changes = append(diff.Changes, ComponentChange{Name: name, Change: ComponentRemoved, From: &fromComponent})
+		default:
+			change := ComponentChange{
+				Name:            name,
+				Change:          ComponentChanged,
+				From:            &fromComponent,
+				To:              &toComponent,
+				ImageChanged:    imageDigest(fromComponent.Image) != imageDigest(toComponent.Image),
+				RevisionChanged: fromComponent.SourceRevision != toComponent.SourceRevision,
+			}
+			if !change.ImageChanged && !change.RevisionChanged {
+				diff.Unchanged = append(diff.Unchanged, name)
+				continue
+			}
+			diff.Changes = append(diff.Changes, change)
+		}
+	}
+
+	return diff
+}
+
+// imageDigest returns the digest of the image, the image itself when it cannot be parsed
+func imageDigest(image string) string {
+	imageURL, err := utils.ParseImageURL(image)
+	if err != nil {
+		return image
+	}
+	return imageURL.Digest()
+}
+
+// LastReleasedSnapshot returns the snapshot of the most recently completed Released release of the application
+// in the namespace. The excluded snapshot is skipped, a snapshot is not compared with itself.
+func LastReleasedSnapshot(ctx context.Context, k8sClient client.Client, namespace, application, excluded string) (string, error) {
+	releases, err := ListReleases(ctx, k8sClient, ReleaseFilter{Application: application, Status: ReleaseStatusReleased}, []string{namespace})
+	if err != nil {
+		return "", err
+	}
+
+	releases = lo.Filter(releases, func(r ReleaseSummary, _ int) bool {
+		return r.Snapshot != excluded && r.CompletionTime != nil
+	})
+
+	if len(releases) == 0 {
+		return "", fmt.Errorf("no released snapshot found for application %s in namespace %s", application, namespace)
+	}
+
+	latest := lo.MaxBy(releases, func(a, b ReleaseSummary) bool {
+		return a.CompletionTime.After(b.CompletionTime.Time)
+	})
+
+	return latest.Snapshot, nil
+}
@@ -0,0 +1,67 @@
+package metadata
+
+import (
+	"context"
+	"time"
+
+	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DiffSnapshots", func() {
+	withComponent := func(snapshot *applicationapi.Snapshot, name, image, revision string) *applicationapi.Snapshot {
+		component := snapshot.Spec.Components[0].DeepCopy()
+		component.Name = name
+		component.ContainerImage = image
+		component.Source.GitSource.Revision = revision
+		snapshot.Spec.Components = append(snapshot.Spec.Components, *component)
+		return snapshot
+	}
+
+	It("reports added, removed and changed components", func() {
+		from := testSnapshot("snapshot-a", testDigest)
+		from = withComponent(from, "removed", "quay.io/org/removed@"+testDigest, "abc123")
+		from = withComponent(from, "rebuilt", "quay.io/org/rebuilt@"+testDigest, "abc123")
+		from = withComponent(from, "moved", "quay.io/org/moved@"+testDigest, "abc123")
+
+		to := testSnapshot("snapshot-b", testOtherDigest)
+		to.Spec.Components[0].Source.GitSource.Revision = "def456"
+		to = withComponent(to, "added", "quay.io/org/added@"+testDigest, "abc123")
+		to = withComponent(to, "rebuilt", "quay.io/org/rebuilt@"+testOtherDigest, "abc123")
+		// same digest, other repository
+		to = withComponent(to, "moved", "quay.io/other/moved@"+testDigest, "abc123")
+
+		diff := DiffSnapshots(from, to)
+		Expect(diff.Unchanged).To(Equal([]string{"moved"}))
+		Expect(diff.Changes).To(HaveExactElements(
+			And(HaveField("Name", "added"), HaveField("Change", ComponentAdded)),
+			And(HaveField("Name", "my-component"), HaveField("Change", ComponentChanged),
+				HaveField("ImageChanged", true), HaveField("RevisionChanged", true)),
+			And(HaveField("Name", "rebuilt"), HaveField("Change", ComponentChanged),
+				HaveField("ImageChanged", true), HaveField("RevisionChanged", false)),
+			And(HaveField("Name", "removed"), HaveField("Change", ComponentRemoved)),
+		))
+		Expect(diff.String()).To(ContainSubstring("Revision: abc123 -> def456"))
+	})
+})
+
+var _ = Describe("LastReleasedSnapshot", func() {
+	It("returns the snapshot of the most recently completed release", func() {
+		t0 := time.Date(2025, time.May, 1, 10, 0, 0, 0, time.UTC)
+		k8sClient := testClient(
+			testReleasePlan("my-rp", true),
+			testRelease("release-ga", "my-rp", "snapshot-a", true, t0),
+			testRelease("release-z", "my-rp", "snapshot-b", true, t0.Add(time.Hour)),
+			testRelease("release-failed", "my-rp", "snapshot-c", false, t0.Add(2*time.Hour)),
+			testRelease("release-new", "my-rp", "snapshot-new", true, t0.Add(3*time.Hour)),
+		)
+
+		snapshot, err := LastReleasedSnapshot(context.Background(), k8sClient, testTenantNamespace, "my-application", "snapshot-new")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(snapshot).To(Equal("snapshot-b"))
+
+		_, err = LastReleasedSnapshot(context.Background(), k8sClient, testTenantNamespace, "other-application", "snapshot-new")
+		Expect(err).To(MatchError(ContainSubstring("no released snapshot found")))
+	})
+})
@@ -0,0 +1,193 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/eguzki/konfluxctl/internal/output"
+	"github.com/eguzki/konfluxctl/internal/utils"
+)
+
+// ComponentSummary is one component of a snapshot
+type ComponentSummary struct {
+	Name           string `json:"name"`
+	Image          string `json:"image"`
+	SourceURL      string `json:"sourceURL,omitempty"`
+	SourceRevision string `json:"sourceRevision,omitempty"`
+}
+
+// SnapshotSummary is one snapshot with its components
+type SnapshotSummary struct {
+	Name         string             `json:"name"`
+	Namespace    string             `json:"namespace"`
+	Application  string             `json:"application"`
+	CreationTime metav1.Time        `json:"creationTime"`
+	Components   []ComponentSummary `json:"components"`
+	// MatchedComponent is the component built as the image, only set by FindSnapshotsByImage
+	MatchedComponent string `json:"matchedComponent,omitempty"`
+}
+
+func (s *SnapshotSummary) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Snapshot: %s/%s\nApplication: %s\nCreated: %s\nComponents:\n",
+		s.Namespace, s.Name, s.Application, completionTimeString(&s.CreationTime))
+	writeComponents(&b, s.Components)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// SnapshotSummaryList is a list of snapshots, most recently created first
+type SnapshotSummaryList []SnapshotSummary
+
+// Table has a MATCHED COMPONENT column when the snapshots were found by image, see FindSnapshotsByImage
+func (l SnapshotSummaryList) Table() *output.Table {
+	withMatch := lo.ContainsBy(l, func(s SnapshotSummary) bool { return s.MatchedComponent != "" })
+
+	columns := []output.Column{{Name: "NAMESPACE"}, {Name: "NAME"}, {Name: "APPLICATION"}, {Name: "COMPONENTS"}, {Name: "CREATED"}}
+	if withMatch {
+		columns = append(columns, output.Column{Name: "MATCHED COMPONENT"})
+	}
+	columns = append(columns, output.Column{Name: "COMPONENT NAMES", Wide: true})
+
+	return &output.Table{
+		Columns: columns,
+		Rows: lo.Map(l, func(s SnapshotSummary, _ int) []string {
+			row := []string{s.Namespace, s.Name, s.Application, fmt.Sprint(len(s.Components)), timeCell(&s.CreationTime)}
+			if withMatch {
+				row = append(row, cell(s.MatchedComponent))
+			}
+			names := lo.Map(s.Components, func(c ComponentSummary, _ int) string { return c.Name })
+			return append(row, cell(strings.Join(names, ",")))
+		}),
+	}
+}
+
+func (l SnapshotSummaryList) Names() []string {
+	return lo.Map(l, func(s SnapshotSummary, _ int) string { return applicationName("snapshot", s.Name) })
+}
+
+// Table is the one row table of the snapshot, see SnapshotSummaryList.Table
+func (s *SnapshotSummary) Table() *output.Table {
+	return SnapshotSummaryList{*s}.Table()
+}
+
+func (s *SnapshotSummary) Names() []string {
+	return SnapshotSummaryList{*s}.Names()
+}
+
+// ListSnapshots returns the snapshots of the application, every snapshot when the application is empty.
+// Snapshots are looked up in the given namespaces, or across all namespaces the user can read when none is given.
+func ListSnapshots(ctx context.Context, k8sClient client.Client, application string, namespaces []string) (SnapshotSummaryList, error) {
+	snapshots, err := listSnapshots(ctx, k8sClient, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	result := SnapshotSummaryList{}
+	for idx := range snapshots {
+		if application != "" && snapshots[idx].Spec.Application != application {
+			continue
+		}
+		result = append(result, summarizeSnapshot(&snapshots[idx]))
+	}
+
+	sortSnapshots(result)
+	return result, nil
+}
+
+// GetSnapshot returns the snapshot with its components
+func GetSnapshot(ctx context.Context, k8sClient client.Client, namespace, name string) (*SnapshotSummary, error) {
+	snapshot := &applicationapi.Snapshot{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, snapshot); err != nil {
+		return nil, err
+	}
+
+	summary := summarizeSnapshot(snapshot)
+	return &summary, nil
+}
+
+// FindSnapshotsByImage returns the snapshots with a component built as the image.
+// Components are matched like the lineage search does, see ReleaseElement.Children.
+// There is no ReleasePlanAdmission mapping to narrow the platform match, every component is a candidate.
+func FindSnapshotsByImage(ctx context.Context, k8sClient client.Client, imageURL *utils.ImageURL, namespaces []string) (SnapshotSummaryList, error) {
+	snapshots, err := listSnapshots(ctx, k8sClient, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	result := SnapshotSummaryList{}
+	for idx := range snapshots {
+		component, _, ok := findComponent(ctx, snapshots[idx].Spec.Components, imageURL, nil)
+		if !ok {
+			continue
+		}
+		summary := summarizeSnapshot(&snapshots[idx])
+		summary.MatchedComponent = component.Name
+		result = append(result, summary)
+	}
+
+	sortSnapshots(result)
+	return result, nil
+}
+
+func listSnapshots(ctx context.Context, k8sClient client.Client, namespaces []string) ([]applicationapi.Snapshot, error) {
+	lists, err := listInNamespaces(ctx, k8sClient, namespaces, func() *applicationapi.SnapshotList {
+		return &applicationapi.SnapshotList{}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []applicationapi.Snapshot{}
+	for _, list := range lists {
+		snapshots = append(snapshots, list.Items...)
+	}
+	return snapshots, nil
+}
+
+func summarizeSnapshot(snapshot *applicationapi.Snapshot) SnapshotSummary {
+	return SnapshotSummary{
+		Name:         snapshot.Name,
+		Namespace:    snapshot.Namespace,
+		Application:  snapshot.Spec.Application,
+		CreationTime: snapshot.CreationTimestamp,
+		Components:   componentSummaries(snapshot),
+	}
+}
+
+func componentSummaries(snapshot *applicationapi.Snapshot) []ComponentSummary {
+	components := []ComponentSummary{}
+	for _, component := range snapshot.Spec.Components {
+		summary := ComponentSummary{Name: component.Name, Image: component.ContainerImage}
+		if component.Source.GitSource != nil {
+			summary.SourceURL = component.Source.GitSource.URL
+			summary.SourceRevision = component.Source.GitSource.Revision
+		}
+		components = append(components, summary)
+	}
+	return components
+}
+
+func writeComponents(b *strings.Builder, components []ComponentSummary) {
+	for _, component := range components {
+		fmt.Fprintf(b, "  %s\n    Image: %s\n", component.Name, component.Image)
+		if component.SourceURL != "" {
+			fmt.Fprintf(b, "    Source: %s@%s\n", component.SourceURL, component.SourceRevision)
+		}
+	}
+}
+
+func sortSnapshots(snapshots SnapshotSummaryList) {
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		if !snapshots[i].CreationTime.Equal(&snapshots[j].CreationTime) {
+			return snapshots[j].CreationTime.Before(&snapshots[i].CreationTime)
+		}
+		return snapshots[i].Namespace+"/"+snapshots[i].Name < snapshots[j].Namespace+"/"+snapshots[j].Name
+	})
+}
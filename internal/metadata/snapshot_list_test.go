@@ -0,0 +1,93 @@
+package metadata
+
+import (
+	"context"
+	"time"
+
+	applicationapi "github.com/konflux-ci/application-api/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/eguzki/konfluxctl/internal/utils"
+)
+
+var _ = Describe("Snapshots", func() {
+	var (
+		ctx       context.Context
+		k8sClient client.Client
+	)
+
+	snapshotNames := func(snapshots SnapshotSummaryList) []string {
+		return lo.Map(snapshots, func(s SnapshotSummary, _ int) string { return s.Name })
+	}
+
+	createdAt := func(snapshot *applicationapi.Snapshot, created time.Time) *applicationapi.Snapshot {
+		snapshot.CreationTimestamp = metav1.Time{Time: created}
+		return snapshot
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		t0 := time.Date(2025, time.May, 1, 10, 0, 0, 0, time.UTC)
+
+		otherApp := createdAt(testSnapshot("snapshot-other-app", testDigest), t0.Add(2*time.Hour))
+		otherApp.Spec.Application = "other-application"
+
+		k8sClient = testClient(
+			createdAt(testSnapshot("snapshot-a", testDigest), t0),
+			createdAt(testSnapshot("snapshot-b", testOtherDigest), t0.Add(time.Hour)),
+			otherApp,
+		)
+	})
+
+	It("lists the snapshots of the application, most recent first", func() {
+		snapshots, err := ListSnapshots(ctx, k8sClient, "my-application", nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(snapshotNames(snapshots)).To(Equal([]string{"snapshot-b", "snapshot-a"}))
+
+		snapshots, err = ListSnapshots(ctx, k8sClient, "", []string{testTenantNamespace})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(snapshotNames(snapshots)).To(Equal([]string{"snapshot-other-app", "snapshot-b", "snapshot-a"}))
+	})
+
+	It("gets the snapshot with its components", func() {
+		snapshot, err := GetSnapshot(ctx, k8sClient, testTenantNamespace, "snapshot-a")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(snapshot.Application).To(Equal("my-application"))
+		Expect(snapshot.Components).To(Equal([]ComponentSummary{{
+			Name:           "my-component",
+			Image:          "quay.io/redhat-user-workloads/my-tenant/my-component@" + testDigest,
+			SourceURL:      "https://github.com/org/my-app",
+			SourceRevision: "abc123",
+		}}))
+	})
+
+	It("finds the snapshots by image digest", func() {
+		imageURL, err := utils.ParseImageURL(testRepository + "@" + testDigest)
+		Expect(err).ToNot(HaveOccurred())
+
+		snapshots, err := FindSnapshotsByImage(ctx, k8sClient, imageURL, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(snapshotNames(snapshots)).To(Equal([]string{"snapshot-other-app", "snapshot-a"}))
+		Expect(snapshots[0].MatchedComponent).To(Equal("my-component"))
+	})
+
+	It("shows the matched component column of found snapshots", func() {
+		imageURL, err := utils.ParseImageURL(testRepository + "@" + testDigest)
+		Expect(err).ToNot(HaveOccurred())
+
+		found, err := FindSnapshotsByImage(ctx, k8sClient, imageURL, nil)
+		Expect(err).ToNot(HaveOccurred())
+		table := found.Table()
+		Expect(table.Columns[5].Name).To(Equal("MATCHED COMPONENT"))
+		Expect(table.Rows[0][5]).To(Equal("my-component"))
+		Expect(found.Names()).To(ContainElement("snapshot.appstudio.redhat.com/snapshot-a"))
+
+		listed, err := ListSnapshots(ctx, k8sClient, "", nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(listed.Table().Columns).ToNot(ContainElement(HaveField("Name", "MATCHED COMPONENT")))
+	})
+})
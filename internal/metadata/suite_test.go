@@ -0,0 +1,19 @@
+package metadata
+
+import (
+	"log/slog"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMetadata(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Metadata Suite")
+}
+
+var _ = BeforeSuite(func() {
+	By("Before suite")
+	slog.SetLogLoggerLevel(slog.LevelDebug)
+})
@@ -0,0 +1,226 @@
+// Package output prints command results in the format selected with -o, like kubectl does
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const (
+	// FormatText is the human readable rendering of the object, its String method
+	FormatText = ""
+	// FormatTable is one row per object, see Tabular
+	FormatTable = "table"
+	// FormatWide is FormatTable with additional columns
+	FormatWide = "wide"
+	// FormatName is the name of every object, see Named
+	FormatName       = "name"
+	FormatJSON       = "json"
+	FormatYAML       = "yaml"
+	FormatJSONPath   = "jsonpath"
+	FormatGoTemplate = "go-template"
+)
+
+// templateFormats take the template after "=", e.g. jsonpath={.name}
+var templateFormats = []string{FormatJSONPath, FormatGoTemplate}
+
+// Column of a table. Wide columns are only shown by FormatWide.
+type Column struct {
+	Name string
+	Wide bool
+}
+
+// Table is the rendering of objects by FormatTable and FormatWide
+type Table struct {
+	Columns []Column
+	Rows    [][]string
+}
+
+// Tabular objects are printed as a table with FormatTable and FormatWide.
+// Single objects return a one row table, lists one row per item.
+type Tabular interface {
+	Table() *Table
+}
+
+// Named objects are printed with FormatName, one name per line
+type Named interface {
+	Names() []string
+}
+
+// Flags is the -o flag of a command. JSON, YAML, jsonpath and go-template are supported by every command,
+// the other formats only by the commands declaring them.
+type Flags struct {
+	value         string
+	defaultFormat string
+	formats       []string
+}
+
+// NewFlags returns the -o flag of a command with the default and the additional formats it supports.
+// Commands printing one object usually default to FormatText, commands printing lists to FormatTable.
+func NewFlags(defaultFormat string, formats ...string) *Flags {
+	return &Flags{defaultFormat: defaultFormat, formats: formats}
+}
+
+// AddFlags registers -o, --output-format on the command
+func (f *Flags) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.value, "output-format", "o", f.defaultFormat, fmt.Sprintf("Output format: %s.", f.help()))
+}
+
+func (f *Flags) help() string {
+	formats := lo.Without(lo.Uniq(append([]string{f.defaultFormat}, f.formats...)), FormatText, FormatJSON, FormatYAML)
+	formats = lo.Map(formats, func(format string, _ int) string { return fmt.Sprintf("'%s'", format) })
+	formats = append(formats, "'json'", "'yaml'", "'jsonpath=TEMPLATE'", "'go-template=TEMPLATE'")
+	help := strings.Join(formats, ", ")
+	if f.defaultFormat == FormatText {
+		help += " (default human readable text)"
+	}
+	return help
+}
+
+// Format returns the format name, without the template.
+// Commands use it to handle their own formats before printing.
+func (f *Flags) Format() string {
+	name, _, _ := strings.Cut(f.value, "=")
+	return name
+}
+
+// Printer validates the -o value and returns the printer of the format.
+// Unknown formats and invalid templates are an error.
+func (f *Flags) Printer() (*Printer, error) {
+	name, tmpl, hasTemplate := strings.Cut(f.value, "=")
+
+	if slices.Contains(templateFormats, name) {
+		if !hasTemplate || tmpl == "" {
+			return nil, fmt.Errorf("output format %q requires a template, e.g. %s={.name}", name, name)
+		}
+		return newTemplatePrinter(name, tmpl)
+	}
+
+	supported := name == FormatJSON || name == FormatYAML || name == f.defaultFormat || slices.Contains(f.formats, name)
+	if hasTemplate || !supported {
+		return nil, fmt.Errorf("unknown output format %q, valid formats: %s", f.value, f.help())
+	}
+
+	return &Printer{format: name}, nil
+}
+
+// Printer writes objects in one format
+type Printer struct {
+	format     string
+	jsonPath   *jsonpath.JSONPath
+	goTemplate *template.Template
+}
+
+func newTemplatePrinter(format, tmpl string) (*Printer, error) {
+	p := &Printer{format: format}
+	switch format {
+	case FormatJSONPath:
+		p.jsonPath = jsonpath.New("output").AllowMissingKeys(true)
+		if err := p.jsonPath.Parse(tmpl); err != nil {
+			return nil, fmt.Errorf("parsing jsonpath %s: %w", tmpl, err)
+		}
+	case FormatGoTemplate:
+		t, err := template.New("output").Parse(tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("parsing go-template %s: %w", tmpl, err)
+		}
+		p.goTemplate = t
+	}
+	return p, nil
+}
+
+// Print writes the object. Templates are executed on the object as printed by FormatJSON,
+// so lists are arrays: -o jsonpath='{[*].name}'.
+func (p *Printer) Print(w io.Writer, obj any) error {
+	switch p.format {
+	case FormatJSON:
+		jsonBytes, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(jsonBytes))
+		return err
+	case FormatYAML:
+		yamlBytes, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(yamlBytes)
+		return err
+	case FormatJSONPath, FormatGoTemplate:
+		return p.printTemplate(w, obj)
+	case FormatName:
+		named, ok := obj.(Named)
+		if !ok {
+			return fmt.Errorf("output format %q is not supported by %T", p.format, obj)
+		}
+		for _, name := range named.Names() {
+			if _, err := fmt.Fprintln(w, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatTable, FormatWide:
+		tabular, ok := obj.(Tabular)
+		if !ok {
+			return fmt.Errorf("output format %q is not supported by %T", p.format, obj)
+		}
+		return printTable(w, tabular.Table(), p.format == FormatWide)
+	default:
+		_, err := fmt.Fprintln(w, obj)
+		return err
+	}
+}
+
+func (p *Printer) printTemplate(w io.Writer, obj any) error {
+	// templates see the JSON field names, not the Go ones
+	jsonBytes, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	var data any
+	if err := json.Unmarshal(jsonBytes, &data); err != nil {
+		return err
+	}
+
+	if p.jsonPath != nil {
+		return p.jsonPath.Execute(w, data)
+	}
+	return p.goTemplate.Execute(w, data)
+}
+
+func printTable(w io.Writer, table *Table, wide bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	columns := []int{}
+	for idx, column := range table.Columns {
+		if wide || !column.Wide {
+			columns = append(columns, idx)
+		}
+	}
+
+	row := func(cells []string) {
+		values := make([]string, 0, len(columns))
+		for _, idx := range columns {
+			values = append(values, cells[idx])
+		}
+		_, _ = fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+
+	row(lo.Map(table.Columns, func(column Column, _ int) string { return column.Name }))
+	for _, cells := range table.Rows {
+		row(cells)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,134 @@
+package output
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+)
+
+type testItem struct {
+	Name  string `json:"name"`
+	Owner string `json:"owner,omitempty"`
+}
+
+func (i testItem) String() string {
+	return "Item: " + i.Name
+}
+
+type testList []testItem
+
+func (l testList) Table() *Table {
+	table := &Table{Columns: []Column{{Name: "NAME"}, {Name: "OWNER", Wide: true}}}
+	for _, item := range l {
+		table.Rows = append(table.Rows, []string{item.Name, item.Owner})
+	}
+	return table
+}
+
+func (l testList) Names() []string {
+	names := []string{}
+	for _, item := range l {
+		names = append(names, "item/"+item.Name)
+	}
+	return names
+}
+
+var _ = Describe("Printer", func() {
+	items := testList{{Name: "a", Owner: "alice"}, {Name: "b"}}
+
+	render := func(flags *Flags, value string, obj any) (string, error) {
+		cmd := &cobra.Command{}
+		flags.AddFlags(cmd)
+		if value != "" {
+			Expect(cmd.Flags().Set("output-format", value)).To(Succeed())
+		}
+		printer, err := flags.Printer()
+		if err != nil {
+			return "", err
+		}
+		var out bytes.Buffer
+		err = printer.Print(&out, obj)
+		return out.String(), err
+	}
+
+	It("prints the default format", func() {
+		out, err := render(NewFlags(FormatText), "", items[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal("Item: a\n"))
+
+		out, err = render(NewFlags(FormatTable), "", items)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal("NAME\na\nb\n"))
+	})
+
+	It("shows wide columns with wide", func() {
+		out, err := render(NewFlags(FormatTable, FormatWide), "wide", items)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal("NAME  OWNER\na     alice\nb     \n"))
+	})
+
+	It("prints JSON and YAML", func() {
+		out, err := render(NewFlags(FormatText), "json", items)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal(`[{"name":"a","owner":"alice"},{"name":"b"}]` + "\n"))
+
+		out, err = render(NewFlags(FormatText), "yaml", items[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal("name: a\nowner: alice\n"))
+	})
+
+	It("executes templates on the JSON document", func() {
+		out, err := render(NewFlags(FormatTable), "jsonpath={[*].name}", items)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal("a b"))
+
+		out, err = render(NewFlags(FormatText), "go-template={{.name}}/{{.owner}}", items[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal("a/alice"))
+	})
+
+	It("prints names", func() {
+		out, err := render(NewFlags(FormatTable, FormatName), "name", items)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal("item/a\nitem/b\n"))
+	})
+
+	It("rejects unknown and unsupported formats", func() {
+		_, err := render(NewFlags(FormatTable, FormatWide), "xml", items)
+		Expect(err).To(MatchError(`unknown output format "xml", valid formats: 'table', 'wide', 'json', 'yaml', 'jsonpath=TEMPLATE', 'go-template=TEMPLATE'`))
+
+		_, err = render(NewFlags(FormatText), "name", items)
+		Expect(err).To(MatchError(ContainSubstring(`unknown output format "name"`)))
+
+		_, err = render(NewFlags(FormatText), "json=x", items)
+		Expect(err).To(MatchError(ContainSubstring("unknown output format")))
+	})
+
+	It("rejects missing and invalid templates", func() {
+		_, err := render(NewFlags(FormatText), "jsonpath", items)
+		Expect(err).To(MatchError(ContainSubstring("requires a template")))
+
+		_, err = render(NewFlags(FormatText), "go-template={{.name", items)
+		Expect(err).To(MatchError(ContainSubstring("parsing go-template")))
+	})
+
+	It("fails on objects not supporting the format", func() {
+		_, err := render(NewFlags(FormatText, FormatTable), "table", items[0])
+		Expect(err).To(MatchError(ContainSubstring("not supported by output.testItem")))
+	})
+
+	It("documents the formats in the flag help", func() {
+		cmd := &cobra.Command{}
+		NewFlags(FormatText, FormatTable).AddFlags(cmd)
+		Expect(cmd.Flags().Lookup("output-format").Usage).To(Equal(
+			"Output format: 'table', 'json', 'yaml', 'jsonpath=TEMPLATE', 'go-template=TEMPLATE' (default human readable text)."))
+
+		// json and yaml are listed once, whatever the default
+		cmd = &cobra.Command{}
+		NewFlags(FormatYAML, FormatName).AddFlags(cmd)
+		Expect(cmd.Flags().Lookup("output-format").Usage).To(Equal(
+			"Output format: 'name', 'json', 'yaml', 'jsonpath=TEMPLATE', 'go-template=TEMPLATE'."))
+	})
+})
@@ -0,0 +1,142 @@
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AuthFileEnvVar overrides the location of the registry credentials file, as podman and skopeo do
+const AuthFileEnvVar = "REGISTRY_AUTH_FILE"
+
+const dockerHubAuthKey = "https://index.docker.io/v1/"
+
+// Credentials to authenticate against a registry
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// authFile is the docker config.json format, only the static credentials
+type authFile struct {
+	Auths map[string]struct {
+		Auth          string `json:"auth"`
+		Username      string `json:"username"`
+		Password      string `json:"password"`
+		IdentityToken string `json:"identitytoken"`
+	} `json:"auths"`
+}
+
+// DefaultAuthFile returns the credentials file location:
+// $REGISTRY_AUTH_FILE, $DOCKER_CONFIG/config.json or ~/.docker/config.json
+func DefaultAuthFile() (string, error) {
+	if path, ok := os.LookupEnv(AuthFileEnvVar); ok && path != "" {
+		return path, nil
+	}
+
+	if dir, ok := os.LookupEnv("DOCKER_CONFIG"); ok && dir != "" {
+		return filepath.Join(dir, "config.json"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".docker", "config.json"), nil
+}
+
+// LoadCredentials reads the credentials of the repository on the registry host from the auth file.
+// Keys may be scoped to a repository path, as podman and skopeo write them: the longest one
+// the repository falls under wins over the host wide entry.
+// A missing file or host is not an error, nil credentials are returned.
+func LoadCredentials(path, host, repository string) (*Credentials, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading registry auth file: %w", err)
+	}
+
+	file := &authFile{}
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("parsing registry auth file %s: %w", path, err)
+	}
+
+	key, found := "", false
+	for candidate := range file.Auths {
+		if authFileHost(candidate) != host {
+			continue
+		}
+		prefix := authFileRepository(candidate)
+		if prefix != "" && repository != prefix && !strings.HasPrefix(repository, prefix+"/") {
+			continue
+		}
+		// map iteration order is random, ties are broken by key to stay deterministic
+		if found {
+			current := authFileRepository(key)
+			if len(prefix) < len(current) || (len(prefix) == len(current) && candidate > key) {
+				continue
+			}
+		}
+		key, found = candidate, true
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	entry := file.Auths[key]
+	if entry.IdentityToken != "" {
+		return &Credentials{Username: "<token>", Password: entry.IdentityToken}, nil
+	}
+
+	if entry.Username != "" {
+		return &Credentials{Username: entry.Username, Password: entry.Password}, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s credentials: %w", key, err)
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid %s credentials: expected username:password", key)
+	}
+	return &Credentials{Username: username, Password: password}, nil
+}
+
+// authFileHost normalizes auth file keys, which may be URLs or include a repository path
+func authFileHost(key string) string {
+	if key == dockerHubAuthKey {
+		return dockerHubHost
+	}
+	key = strings.TrimPrefix(key, "https://")
+	key = strings.TrimPrefix(key, "http://")
+	host, _, _ := strings.Cut(key, "/")
+	if host == "docker.io" || host == "index.docker.io" {
+		return dockerHubHost
+	}
+	return host
+}
+
+// authFileRepository returns the repository path auth file keys are scoped to, empty for host wide keys
+func authFileRepository(key string) string {
+	if key == dockerHubAuthKey {
+		return ""
+	}
+	key = strings.TrimPrefix(key, "https://")
+	key = strings.TrimPrefix(key, "http://")
+	_, repository, _ := strings.Cut(key, "/")
+	return strings.TrimSuffix(repository, "/")
+}
@@ -0,0 +1,408 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/eguzki/konfluxctl/internal/utils"
+)
+
+// dockerHubHost is the API endpoint of the docker.io registry
+const dockerHubHost = "registry-1.docker.io"
+
+const (
+	ociIndexMediaType           = "application/vnd.oci.image.index.v1+json"
+	dockerManifestListMediaType = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+// manifestMediaTypes are the accepted manifest media types. Indexes go first,
+// the digest of a multi-arch image is the digest of its index.
+var manifestMediaTypes = []string{
+	ociIndexMediaType,
+	dockerManifestListMediaType,
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// ErrNetworkDisabled is returned when resolving requires the registry and the resolver must not reach it
+var ErrNetworkDisabled = errors.New("registry requests are disabled")
+
+// Resolver resolves image tags to digests and image indexes to their platform manifests
+// using the OCI distribution API. It is safe for concurrent use.
+type Resolver struct {
+	client   *http.Client
+	authFile string
+	// offline resolvers only accept digest references, see WithNetwork
+	offline bool
+
+	mu sync.Mutex
+	// indexes memoizes the platform manifests by image, released images are immutable by digest
+	indexes map[string]map[string]string
+	// indexErrors memoizes the failures too: an unreachable, or unreadable, image fails the same way every time
+	indexErrors map[string]error
+}
+
+// imageIndex is the subset of the OCI image index, and docker manifest list, read by the resolver
+type imageIndex struct {
+	MediaType string `json:"mediaType"`
+	Manifests []struct {
+		Digest   string `json:"digest"`
+		Platform *struct {
+			OS           string `json:"os"`
+			Architecture string `json:"architecture"`
+			Variant      string `json:"variant"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
+type ResolverOption func(*Resolver)
+
+// WithHTTPClient sets the client used to reach the registries
+func WithHTTPClient(client *http.Client) ResolverOption {
+	return func(r *Resolver) {
+		r.client = client
+	}
+}
+
+// WithAuthFile sets the docker config.json file the credentials are read from
+func WithAuthFile(path string) ResolverOption {
+	return func(r *Resolver) {
+		r.authFile = path
+	}
+}
+
+// WithNetwork disables registry requests when unset, for replays that must not depend on the registry.
+// Digest references still resolve, tags and image indexes fail with ErrNetworkDisabled.
+func WithNetwork(enabled bool) ResolverOption {
+	return func(r *Resolver) {
+		r.offline = !enabled
+	}
+}
+
+func NewResolver(opts ...ResolverOption) *Resolver {
+	r := &Resolver{client: http.DefaultClient, indexes: map[string]map[string]string{}, indexErrors: map[string]error{}}
+	if path, err := DefaultAuthFile(); err == nil {
+		r.authFile = path
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Resolve returns the image reference pinned by digest.
+// References with a digest are returned as they are, without reaching the registry.
+// References without tag nor digest resolve the "latest" tag.
+func (r *Resolver) Resolve(ctx context.Context, imageURL string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(imageURL)
+	if err != nil {
+		return "", fmt.Errorf("error parsing image reference: %w", err)
+	}
+
+	if _, ok := named.(reference.Canonical); ok {
+		return imageURL, nil
+	}
+
+	tagged, ok := reference.TagNameOnly(named).(reference.NamedTagged)
+	if !ok {
+		return "", fmt.Errorf("image reference has neither tag nor digest: %s", imageURL)
+	}
+
+	if r.offline {
+		return "", fmt.Errorf("resolving %s: %w, pass the image by digest", reference.FamiliarString(tagged), ErrNetworkDisabled)
+	}
+
+	dgst, err := r.manifestDigest(ctx, reference.Domain(tagged), reference.Path(tagged), tagged.Tag())
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", reference.FamiliarString(tagged), err)
+	}
+
+	canonical, err := reference.WithDigest(reference.TrimNamed(tagged), dgst)
+	if err != nil {
+		return "", err
+	}
+
+	slog.Debug("registry", "tag", reference.FamiliarString(tagged), "digest", dgst)
+
+	return reference.FamiliarString(canonical), nil
+}
+
+// PlatformManifests returns the platform of each manifest of the image index, keyed by manifest digest.
+// It returns an empty map when the image is a single platform manifest.
+func (r *Resolver) PlatformManifests(ctx context.Context, imageURL *utils.ImageURL) (map[string]string, error) {
+	key := fmt.Sprintf("%s/%s@%s", imageURL.Hostname(), imageURL.Repository(), imageURL.Digest())
+
+	r.mu.Lock()
+	manifests, ok := r.indexes[key]
+	err, failed := r.indexErrors[key]
+	r.mu.Unlock()
+	if ok {
+		return manifests, nil
+	}
+	if failed {
+		return nil, err
+	}
+
+	manifests, err = r.fetchPlatformManifests(ctx, imageURL)
+	if err != nil {
+		// cancellation says nothing about the image
+		if ctx.Err() == nil {
+			r.mu.Lock()
+			r.indexErrors[key] = err
+			r.mu.Unlock()
+		}
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.indexes[key] = manifests
+	r.mu.Unlock()
+
+	return manifests, nil
+}
+
+func (r *Resolver) fetchPlatformManifests(ctx context.Context, imageURL *utils.ImageURL) (map[string]string, error) {
+	if r.offline {
+		return nil, fmt.Errorf("reading image index of %s: %w", imageURL.FamiliarName(), ErrNetworkDisabled)
+	}
+
+	host := registryHost(imageURL.Hostname())
+	resp, err := r.do(ctx, http.MethodGet, manifestURL(host, imageURL.Repository(), imageURL.Digest()), host, imageURL.Repository())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	index := &imageIndex{}
+	if err := json.NewDecoder(resp.Body).Decode(index); err != nil {
+		return nil, fmt.Errorf("parsing manifest of %s: %w", imageURL.FamiliarName(), err)
+	}
+
+	mediaType := index.MediaType
+	if mediaType == "" {
+		mediaType, _, _ = strings.Cut(resp.Header.Get("Content-Type"), ";")
+	}
+
+	manifests := map[string]string{}
+	if mediaType != ociIndexMediaType && mediaType != dockerManifestListMediaType {
+		return manifests, nil
+	}
+
+	for _, manifest := range index.Manifests {
+		// attestations and other artifacts have no platform
+		if manifest.Platform == nil {
+			continue
+		}
+		manifests[manifest.Digest] = platformString(manifest.Platform.OS, manifest.Platform.Architecture, manifest.Platform.Variant)
+	}
+
+	return manifests, nil
+}
+
+// platformString formats platforms like the OCI image spec, os/architecture[/variant]
+func platformString(os, architecture, variant string) string {
+	if variant == "" {
+		return fmt.Sprintf("%s/%s", os, architecture)
+	}
+	return fmt.Sprintf("%s/%s/%s", os, architecture, variant)
+}
+
+func registryHost(domain string) string {
+	if domain == "docker.io" {
+		return dockerHubHost
+	}
+	return domain
+}
+
+func manifestURL(host, repository, reference string) string {
+	return fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, repository, url.PathEscape(reference))
+}
+
+func (r *Resolver) manifestDigest(ctx context.Context, domain, repository, tag string) (digest.Digest, error) {
+	host := registryHost(domain)
+	manifestURL := manifestURL(host, repository, tag)
+
+	resp, err := r.do(ctx, http.MethodHead, manifestURL, host, repository)
+	if err != nil {
+		return "", err
+	}
+	_ = resp.Body.Close()
+
+	if dgst, err := digest.Parse(resp.Header.Get("Docker-Content-Digest")); err == nil {
+		return dgst, nil
+	}
+
+	// the digest header is optional, fetch the manifest and compute it
+	resp, err = r.do(ctx, http.MethodGet, manifestURL, host, repository)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, resp.Body); err != nil {
+		return "", err
+	}
+
+	return digest.NewDigest(digest.SHA256, hash), nil
+}
+
+// do sends the manifest request, going through the registry authentication challenge when required
+func (r *Resolver) do(ctx context.Context, method, manifestURL, host, repository string) (*http.Response, error) {
+	resp, err := r.send(ctx, method, manifestURL, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		_ = resp.Body.Close()
+
+		authorization, err := r.authorize(ctx, resp.Header.Get("WWW-Authenticate"), host, repository)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err = r.send(ctx, method, manifestURL, authorization)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: unexpected status %s", method, manifestURL, resp.Status)
+	}
+
+	return resp, nil
+}
+
+func (r *Resolver) send(ctx context.Context, method, manifestURL, authorization string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	return r.client.Do(req)
+}
+
+// authorize returns the Authorization header answering the registry challenge
+func (r *Resolver) authorize(ctx context.Context, challenge, host, repository string) (string, error) {
+	creds, err := LoadCredentials(r.authFile, host, repository)
+	if err != nil {
+		return "", err
+	}
+
+	scheme, params := parseChallenge(challenge)
+	switch scheme {
+	case "basic":
+		if creds == nil {
+			return "", fmt.Errorf("registry %s requires credentials", host)
+		}
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(creds.Username, creds.Password)
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+		token, err := r.fetchToken(ctx, params, creds, repository)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	default:
+		return "", fmt.Errorf("unsupported registry authentication challenge: %q", challenge)
+	}
+}
+
+// fetchToken implements the docker registry token authentication flow
+func (r *Resolver) fetchToken(ctx context.Context, params map[string]string, creds *Credentials, repository string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("invalid token realm %q", params["realm"])
+	}
+
+	query := realm.Query()
+	if service, ok := params["service"]; ok {
+		query.Set("service", service)
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", repository)
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if creds != nil {
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("requesting registry token: unexpected status %s", resp.Status)
+	}
+
+	tokenResp := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", fmt.Errorf("parsing registry token: %w", err)
+	}
+
+	if tokenResp.Token != "" {
+		return tokenResp.Token, nil
+	}
+	if tokenResp.AccessToken != "" {
+		return tokenResp.AccessToken, nil
+	}
+
+	return "", errors.New("registry token response without token")
+}
+
+// parseChallenge parses a WWW-Authenticate header like
+// Bearer realm="https://auth.example.com/token",service="registry.example.com"
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+
+	for rest != "" {
+		var key, value string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, " ,"), "=")
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		if key = strings.TrimSpace(key); key != "" {
+			params[strings.ToLower(key)] = value
+		}
+	}
+
+	return strings.ToLower(scheme), params
+}
@@ -0,0 +1,245 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/eguzki/konfluxctl/internal/utils"
+)
+
+const (
+	testManifest = `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[` +
+		`{"digest":"sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa","platform":{"os":"linux","architecture":"amd64"}},` +
+		`{"digest":"sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb","platform":{"os":"linux","architecture":"arm64","variant":"v8"}},` +
+		`{"digest":"sha256:cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc"}]}`
+	testToken = "secret-token"
+)
+
+var testDigest = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(testManifest)))
+
+// newTestRegistry serves org/img:1.2.3 behind the docker token authentication flow
+func newTestRegistry(withDigestHeader bool) *httptest.Server {
+	mux := http.NewServeMux()
+	var server *httptest.Server
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "robot" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("scope") != "repository:org/img:pull" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"token":%q}`, testToken)
+	})
+
+	mux.HandleFunc("/v2/org/img/manifests/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			w.Header().Set("WWW-Authenticate",
+				fmt.Sprintf(`Bearer realm="%s/token",service="test-registry",scope="repository:org/img:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if ref := strings.TrimPrefix(r.URL.Path, "/v2/org/img/manifests/"); ref != "1.2.3" && ref != testDigest {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.oci.image.index.v1+json")
+		if withDigestHeader {
+			w.Header().Set("Docker-Content-Digest", testDigest)
+		}
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(testManifest))
+		}
+	})
+
+	server = httptest.NewTLSServer(mux)
+	return server
+}
+
+func writeAuthFile(host string) string {
+	authFile := filepath.Join(GinkgoT().TempDir(), "config.json")
+	auth := base64.StdEncoding.EncodeToString([]byte("robot:pass"))
+	Expect(os.WriteFile(authFile, []byte(fmt.Sprintf(`{"auths":{%q:{"auth":%q}}}`, host, auth)), 0o600)).To(Succeed())
+	return authFile
+}
+
+var _ = Describe("Resolver", func() {
+	var (
+		ctx    context.Context
+		server *httptest.Server
+		host   string
+	)
+
+	JustBeforeEach(func() {
+		host = strings.TrimPrefix(server.URL, "https://")
+		DeferCleanup(server.Close)
+	})
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		server = newTestRegistry(true)
+	})
+
+	It("returns digest references as they are", func() {
+		resolver := NewResolver(WithHTTPClient(server.Client()), WithAuthFile(""))
+		ref := "quay.io/org/img@" + testDigest
+		Expect(resolver.Resolve(ctx, ref)).To(Equal(ref))
+	})
+
+	It("resolves tags to digests going through the token flow", func() {
+		resolver := NewResolver(WithHTTPClient(server.Client()), WithAuthFile(writeAuthFile(host)))
+		Expect(resolver.Resolve(ctx, host+"/org/img:1.2.3")).To(Equal(host + "/org/img@" + testDigest))
+	})
+
+	It("fails without credentials", func() {
+		resolver := NewResolver(WithHTTPClient(server.Client()), WithAuthFile(""))
+		_, err := resolver.Resolve(ctx, host+"/org/img:1.2.3")
+		Expect(err).To(MatchError(ContainSubstring("requesting registry token")))
+	})
+
+	It("fails on unknown tags", func() {
+		resolver := NewResolver(WithHTTPClient(server.Client()), WithAuthFile(writeAuthFile(host)))
+		_, err := resolver.Resolve(ctx, host+"/org/img:9.9.9")
+		Expect(err).To(MatchError(ContainSubstring("404")))
+	})
+
+	It("does not reach the registry without network", func() {
+		requests := 0
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		resolver := NewResolver(WithHTTPClient(server.Client()), WithAuthFile(writeAuthFile(host)), WithNetwork(false))
+
+		ref := host + "/org/img@" + testDigest
+		Expect(resolver.Resolve(ctx, ref)).To(Equal(ref))
+
+		_, err := resolver.Resolve(ctx, host+"/org/img:1.2.3")
+		Expect(err).To(MatchError(ErrNetworkDisabled))
+		Expect(err).To(MatchError(ContainSubstring("pass the image by digest")))
+		Expect(requests).To(BeZero())
+	})
+
+	When("the registry does not return the digest header", func() {
+		BeforeEach(func() {
+			server = newTestRegistry(false)
+		})
+
+		It("computes the digest from the manifest", func() {
+			resolver := NewResolver(WithHTTPClient(server.Client()), WithAuthFile(writeAuthFile(host)))
+			Expect(resolver.Resolve(ctx, host+"/org/img:1.2.3")).To(Equal(host + "/org/img@" + testDigest))
+		})
+	})
+})
+
+var _ = Describe("Resolver platform manifests", func() {
+	It("maps the platform manifests of image indexes", func() {
+		server := newTestRegistry(true)
+		DeferCleanup(server.Close)
+		host := strings.TrimPrefix(server.URL, "https://")
+
+		imageURL, err := utils.ParseImageURL(host + "/org/img@" + testDigest)
+		Expect(err).ToNot(HaveOccurred())
+
+		resolver := NewResolver(WithHTTPClient(server.Client()), WithAuthFile(writeAuthFile(host)))
+		manifests, err := resolver.PlatformManifests(context.Background(), imageURL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifests).To(Equal(map[string]string{
+			"sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa": "linux/amd64",
+			"sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb": "linux/arm64/v8",
+		}))
+
+		// memoized, the registry is not reached again
+		server.Close()
+		Expect(resolver.PlatformManifests(context.Background(), imageURL)).To(HaveLen(2))
+	})
+
+	It("memoizes failures", func() {
+		requests := 0
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		DeferCleanup(server.Close)
+		host := strings.TrimPrefix(server.URL, "https://")
+
+		imageURL, err := utils.ParseImageURL(host + "/org/img@" + testDigest)
+		Expect(err).ToNot(HaveOccurred())
+
+		resolver := NewResolver(WithHTTPClient(server.Client()), WithAuthFile(""))
+		for range 3 {
+			_, err := resolver.PlatformManifests(context.Background(), imageURL)
+			Expect(err).To(MatchError(ContainSubstring("404")))
+		}
+		Expect(requests).To(Equal(1))
+	})
+})
+
+var _ = DescribeTable("parseChallenge",
+	func(challenge, scheme string, params map[string]string) {
+		gotScheme, gotParams := parseChallenge(challenge)
+		Expect(gotScheme).To(Equal(scheme))
+		Expect(gotParams).To(Equal(params))
+	},
+	Entry("bearer", `Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:a/b:pull,push"`, "bearer",
+		map[string]string{"realm": "https://auth.example.com/token", "service": "registry.example.com", "scope": "repository:a/b:pull,push"}),
+	Entry("basic", `Basic realm="Registry Realm"`, "basic", map[string]string{"realm": "Registry Realm"}),
+)
+
+var _ = DescribeTable("authFileHost",
+	func(key, host string) {
+		Expect(authFileHost(key)).To(Equal(host))
+	},
+	Entry("docker hub", "https://index.docker.io/v1/", dockerHubHost),
+	Entry("plain host", "quay.io", "quay.io"),
+	Entry("host with repository", "quay.io/org", "quay.io"),
+	Entry("url", "https://registry.example.com:5000", "registry.example.com:5000"),
+)
+
+var _ = Describe("LoadCredentials", func() {
+	var authFile string
+
+	BeforeEach(func() {
+		authFile = filepath.Join(GinkgoT().TempDir(), "config.json")
+		Expect(os.WriteFile(authFile, []byte(`{"auths":{
+			"quay.io":{"username":"host","password":"host-pass"},
+			"quay.io/org":{"username":"org","password":"org-pass"},
+			"quay.io/org/team":{"username":"team","password":"team-pass"}
+		}}`), 0o600)).To(Succeed())
+	})
+
+	DescribeTable("picks the longest repository prefix",
+		func(repository, username string) {
+			// map iteration order is random, a single lookup may pick the right entry by chance
+			for range 20 {
+				creds, err := LoadCredentials(authFile, "quay.io", repository)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(creds).NotTo(BeNil())
+				Expect(creds.Username).To(Equal(username))
+			}
+		},
+		Entry("repository under the org", "org/img", "org"),
+		Entry("repository under the team", "org/team/img", "team"),
+		Entry("repository sharing the org name prefix", "organization/img", "host"),
+		Entry("repository of another org", "other/img", "host"),
+	)
+
+	It("returns nil credentials for unknown hosts", func() {
+		creds, err := LoadCredentials(authFile, "registry.example.com", "org/img")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(creds).To(BeNil())
+	})
+})
@@ -57,3 +57,14 @@ func ParseImageURL(imageURL string) (*ImageURL, error) {
 		digest:       canonical.Digest().String(),
 	}, nil
 }
+
+// ParseRepository returns the familiar name of the repository of an image reference,
+// dropping any tag or digest: quay.io/org/img:1.0 is quay.io/org/img
+func ParseRepository(imageURL string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(imageURL)
+	if err != nil {
+		return "", fmt.Errorf("error parsing repository: %w", err)
+	}
+
+	return reference.FamiliarName(reference.TrimNamed(named)), nil
+}
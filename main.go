@@ -16,7 +16,9 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"os"
+	"os/signal"
 
 	"github.com/eguzki/konfluxctl/cmd"
 )
@@ -24,7 +26,12 @@ import (
 func main() {
 	rootCmd := cmd.GetRootCmd(os.Args[1:])
 
-	if err := rootCmd.Execute(); err != nil {
+	// cancel running commands on Ctrl+C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		stop()
 		os.Exit(1)
 	}
 }